@@ -0,0 +1,353 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	tracecontext "github.com/lightstep/tracecontext.go"
+	"github.com/lightstep/tracecontext.go/interop"
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+)
+
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitUsage   = 2
+)
+
+const usage = `usage: tracectx <command> [flags] [arguments]
+
+commands:
+  decode [-json] <traceparent> [tracestate]     print the fields, flags and list members
+  validate [-json] <traceparent> [tracestate]   exit with status 1 and the reason if invalid
+  new [-json] [-sampled] [-random]              generate a new traceparent
+  child [-json] [-sampled | -unsampled] <traceparent> [tracestate]
+                                                derive the traceparent of a child span, inheriting the sampled flag by default
+  convert [-json] -from <format> -to <format> <value>
+                                                convert between formats: %s
+  scan [-json] [file ...]                       group the trace context found in logs, read from stdin by default
+  proxy -target <url> [-listen <addr>] [-log <file>] [-repair] [-restart]
+                                                forward requests, recording each hop's trace context as JSONL
+`
+
+type command func(args []string, stdin io.Reader, stdout io.Writer) error
+
+var commands = map[string]command{
+	"decode":   decode,
+	"validate": validate,
+	"new":      newTraceParent,
+	"child":    child,
+	"convert":  convert,
+	"scan":     scan,
+	"proxy":    proxy,
+}
+
+// format converts a trace context header of a particular tracing system to and from a `TraceParent`.
+type format struct {
+	header string
+	parse  func(string) (traceparent.TraceParent, error)
+	format func(traceparent.TraceParent) string
+}
+
+var formats = map[string]format{
+	"w3c":        {"traceparent", traceparent.ParseString, traceparent.TraceParent.String},
+	"b3":         {interop.B3Header, interop.ParseB3, interop.FormatB3},
+	"cloudtrace": {interop.CloudTraceHeader, interop.ParseCloudTrace, interop.FormatCloudTrace},
+	"elastic":    {interop.ElasticTraceParentHeader, interop.ParseElasticTraceParent, traceparent.TraceParent.String},
+	"jaeger":     {interop.JaegerHeader, interop.ParseJaeger, interop.FormatJaeger},
+	"sentry":     {interop.SentryTraceHeader, interop.ParseSentryTrace, interop.FormatSentryTrace},
+	"xray":       {interop.XRayHeader, interop.ParseXRay, interop.FormatXRay},
+}
+
+// errInvalid wraps errors that indicate invalid input, as opposed to incorrect usage.
+type errInvalid struct {
+	err error
+}
+
+func (e errInvalid) Error() string {
+	return e.err.Error()
+}
+
+// errUsage indicates incorrect usage, e.g., a missing argument.
+var errUsage = errors.New("invalid usage")
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintf(stderr, usage, formatNames())
+		return exitUsage
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "tracectx: unknown command %q\n", args[0])
+		fmt.Fprintf(stderr, usage, formatNames())
+		return exitUsage
+	}
+
+	err := cmd(args[1:], stdin, stdout)
+	switch err.(type) {
+	case nil:
+		return exitOK
+	case errInvalid:
+		fmt.Fprintf(stderr, "tracectx: %s\n", err)
+		return exitInvalid
+	default:
+		if err != errUsage && err != flag.ErrHelp {
+			fmt.Fprintf(stderr, "tracectx: %s\n", err)
+		}
+		fmt.Fprintf(stderr, usage, formatNames())
+		return exitUsage
+	}
+}
+
+func formatNames() string {
+	var names []string
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *bool) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	return fs, fs.Bool("json", false, "print JSON")
+}
+
+// parseContext parses a `traceparent` and an optional `tracestate`, both of which must be valid.
+func parseContext(args []string) (tracecontext.TraceContext, error) {
+	var tc tracecontext.TraceContext
+	if len(args) < 1 || len(args) > 2 {
+		return tc, errUsage
+	}
+
+	var err error
+	if tc.TraceParent, err = traceparent.ParseString(args[0]); err != nil {
+		return tc, errInvalid{fmt.Errorf("invalid traceparent: %s", err)}
+	}
+	if len(args) == 2 {
+		if tc.TraceState, err = tracestate.ParseString(args[1]); err != nil {
+			return tc, errInvalid{fmt.Errorf("invalid tracestate: %s", err)}
+		}
+	}
+	return tc, nil
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeContext prints the headers of the trace context, or the JSON form of `tracecontext.TraceContext`.
+func writeContext(w io.Writer, tc tracecontext.TraceContext, asJSON bool) error {
+	if asJSON {
+		return writeJSON(w, tc)
+	}
+	fmt.Fprintf(w, "traceparent: %s\n", tc.TraceParent)
+	if len(tc.TraceState) > 0 {
+		fmt.Fprintf(w, "tracestate: %s\n", tc.TraceState)
+	}
+	return nil
+}
+
+type decodedFlags struct {
+	Sampled bool `json:"sampled"`
+	Random  bool `json:"random"`
+}
+
+type decodedMember struct {
+	Key      string `json:"key"`
+	TenantID string `json:"tenant_id,omitempty"`
+	SystemID string `json:"system_id"`
+	Value    string `json:"value"`
+}
+
+type decoded struct {
+	TraceParent traceparent.Object `json:"traceparent"`
+	Flags       decodedFlags       `json:"flags"`
+	TraceState  []decodedMember    `json:"tracestate"`
+}
+
+func decode(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs, asJSON := newFlagSet("decode")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	tc, err := parseContext(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	d := decoded{
+		TraceParent: tc.TraceParent.Object(),
+		Flags:       decodedFlags{Sampled: tc.TraceParent.Flags.Recorded, Random: tc.TraceParent.Flags.Random},
+		TraceState:  []decodedMember{},
+	}
+	for _, m := range tc.TraceState {
+		d.TraceState = append(d.TraceState, decodedMember{Key: m.Key(), TenantID: m.TenantID, SystemID: m.SystemID, Value: m.Value})
+	}
+
+	if *asJSON {
+		return writeJSON(stdout, d)
+	}
+
+	var flags []string
+	if d.Flags.Sampled {
+		flags = append(flags, "sampled")
+	}
+	if d.Flags.Random {
+		flags = append(flags, "random")
+	}
+	fmt.Fprintf(stdout, "version:   %s\n", d.TraceParent.Version)
+	fmt.Fprintf(stdout, "trace-id:  %s\n", d.TraceParent.TraceID)
+	fmt.Fprintf(stdout, "parent-id: %s\n", d.TraceParent.SpanID)
+	if len(flags) == 0 {
+		flags = append(flags, "none")
+	}
+	fmt.Fprintf(stdout, "flags:     %s (%s)\n", d.TraceParent.Flags, strings.Join(flags, ", "))
+	fmt.Fprintf(stdout, "tracestate: %d members\n", len(d.TraceState))
+	for i, m := range d.TraceState {
+		fmt.Fprintf(stdout, "  %2d. %s = %s\n", i+1, m.Key, m.Value)
+	}
+	return nil
+}
+
+type validated struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+func validate(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs, asJSON := newFlagSet("validate")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	_, err := parseContext(fs.Args())
+	if _, ok := err.(errInvalid); err != nil && !ok {
+		return err
+	}
+
+	if *asJSON {
+		v := validated{Valid: err == nil}
+		if err != nil {
+			v.Error = err.Error()
+		}
+		if jsonErr := writeJSON(stdout, v); jsonErr != nil {
+			return jsonErr
+		}
+	} else if err == nil {
+		fmt.Fprintln(stdout, "valid")
+	}
+	return err
+}
+
+func newTraceParent(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs, asJSON := newFlagSet("new")
+	sampled := fs.Bool("sampled", true, "set the sampled flag")
+	random := fs.Bool("random", true, "set the random flag")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errUsage
+	}
+
+	var tc tracecontext.TraceContext
+	tc.TraceParent.Version = traceparent.Version
+	tc.TraceParent.Flags = traceparent.Flags{Recorded: *sampled, Random: *random}
+	if err := randomID(tc.TraceParent.TraceID[:]); err != nil {
+		return err
+	}
+	if err := randomID(tc.TraceParent.SpanID[:]); err != nil {
+		return err
+	}
+	return writeContext(stdout, tc, *asJSON)
+}
+
+func child(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs, asJSON := newFlagSet("child")
+	sampled := fs.Bool("sampled", false, "set the sampled flag")
+	unsampled := fs.Bool("unsampled", false, "clear the sampled flag")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *sampled && *unsampled {
+		return errors.New("-sampled and -unsampled cannot be used together")
+	}
+	tc, err := parseContext(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	if *sampled || *unsampled {
+		tc.TraceParent.Flags.Recorded = *sampled
+	}
+	tc.TraceParent.Version = traceparent.Version
+	if err := randomID(tc.TraceParent.SpanID[:]); err != nil {
+		return err
+	}
+	return writeContext(stdout, tc, *asJSON)
+}
+
+type converted struct {
+	Format string `json:"format"`
+	Header string `json:"header"`
+	Value  string `json:"value"`
+}
+
+func convert(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs, asJSON := newFlagSet("convert")
+	from := fs.String("from", "w3c", "format of the value")
+	to := fs.String("to", "w3c", "format to convert to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+
+	src, ok := formats[*from]
+	if !ok {
+		return fmt.Errorf("unknown format %q", *from)
+	}
+	dst, ok := formats[*to]
+	if !ok {
+		return fmt.Errorf("unknown format %q", *to)
+	}
+
+	tp, err := src.parse(fs.Arg(0))
+	if err != nil {
+		return errInvalid{fmt.Errorf("invalid %s value: %s", *from, err)}
+	}
+
+	c := converted{Format: *to, Header: dst.header, Value: dst.format(tp)}
+	if *asJSON {
+		return writeJSON(stdout, c)
+	}
+	fmt.Fprintf(stdout, "%s: %s\n", c.Header, c.Value)
+	return nil
+}
+
+// randomID fills the ID with random bytes, retrying in the unlikely event that they are all 0.
+func randomID(id []byte) error {
+	for {
+		if _, err := rand.Read(id); err != nil {
+			return err
+		}
+		for _, b := range id {
+			if b != 0 {
+				return nil
+			}
+		}
+	}
+}
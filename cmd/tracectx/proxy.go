@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/textproto"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	tracecontext "github.com/lightstep/tracecontext.go"
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+)
+
+const (
+	actionForwarded = "forwarded"
+	actionRepaired  = "repaired"
+	actionRestarted = "restarted"
+)
+
+var (
+	traceParentHeader = textproto.CanonicalMIMEHeaderKey("traceparent")
+	traceStateHeader  = textproto.CanonicalMIMEHeaderKey("tracestate")
+)
+
+// headerValues holds the raw `traceparent` and `tracestate` header lines of a request.
+type headerValues struct {
+	TraceParent []string `json:"traceparent,omitempty"`
+	TraceState  []string `json:"tracestate,omitempty"`
+}
+
+func newHeaderValues(h http.Header) headerValues {
+	return headerValues{
+		TraceParent: append([]string(nil), h[traceParentHeader]...),
+		TraceState:  append([]string(nil), h[traceStateHeader]...),
+	}
+}
+
+// hop is a record of a single request passing through the proxy.
+type hop struct {
+	Time   time.Time    `json:"time"`
+	Method string       `json:"method"`
+	URL    string       `json:"url"`
+	Before headerValues `json:"before"`
+	After  headerValues `json:"after"`
+	// Errors are the errors from parsing the incoming headers.
+	Errors []string `json:"errors,omitempty"`
+	// Action is what the proxy did with the trace context: forwarded it unchanged, repaired it, or restarted the trace.
+	Action string `json:"action"`
+}
+
+// recordingProxy is a reverse proxy that records the trace context of each request before and after
+// optionally repairing or restarting it.
+type recordingProxy struct {
+	// repair leniently parses invalid trace context, taking the first of multiple `traceparent` values,
+	// accepting uppercase hex and surrounding whitespace, and dropping an invalid `tracestate`.
+	repair bool
+	// restart replaces an invalid `traceparent`, that could not be repaired, with a new trace.
+	restart bool
+
+	reverse *httputil.ReverseProxy
+
+	mu  sync.Mutex
+	log *json.Encoder
+}
+
+func newRecordingProxy(target *url.URL, log io.Writer, repair, restart bool) *recordingProxy {
+	return &recordingProxy{
+		repair:  repair,
+		restart: restart,
+		reverse: httputil.NewSingleHostReverseProxy(target),
+		log:     json.NewEncoder(log),
+	}
+}
+
+func (p *recordingProxy) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h := hop{
+		Time:   time.Now().UTC(),
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Before: newHeaderValues(req.Header),
+	}
+	h.Action, h.Errors = p.process(req.Header)
+	h.After = newHeaderValues(req.Header)
+
+	p.mu.Lock()
+	p.log.Encode(h)
+	p.mu.Unlock()
+
+	p.reverse.ServeHTTP(w, req)
+}
+
+// process validates the trace context of the headers, repairing or restarting it in place if configured to,
+// and returns the action taken along with any parse errors.
+func (p *recordingProxy) process(headers http.Header) (string, []string) {
+	if len(headers[traceParentHeader]) == 0 {
+		return actionForwarded, nil
+	}
+
+	var errs []string
+	_, err := tracecontext.FromHeaders(headers)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("traceparent: %s", err))
+	}
+	if traceStates := headers[traceStateHeader]; len(traceStates) > 0 {
+		if _, err := tracestate.ParseString(strings.Join(traceStates, ",")); err != nil {
+			errs = append(errs, fmt.Sprintf("tracestate: %s", err))
+		}
+	}
+	if len(errs) == 0 {
+		return actionForwarded, nil
+	}
+
+	if p.repair {
+		tc, err := tracecontext.FromHeadersWithOptions(headers, tracecontext.Options{
+			MultipleHeaders: tracecontext.TakeFirst,
+			CombinedValues:  tracecontext.TakeFirst,
+			Normalizer:      &traceparent.Normalizer{},
+		})
+		if err == nil {
+			setHeaders(headers, tc)
+			return actionRepaired, errs
+		}
+	}
+
+	if err != nil && p.restart {
+		var tc tracecontext.TraceContext
+		tc.TraceParent.Version = traceparent.Version
+		tc.TraceParent.Flags.Random = true
+		if randomID(tc.TraceParent.TraceID[:]) == nil && randomID(tc.TraceParent.SpanID[:]) == nil {
+			setHeaders(headers, tc)
+			return actionRestarted, errs
+		}
+	}
+
+	return actionForwarded, errs
+}
+
+// setHeaders replaces the trace context headers, removing the `tracestate` header rather than forwarding it empty.
+func setHeaders(headers http.Header, tc tracecontext.TraceContext) {
+	tc.SetHeaders(headers)
+	if len(tc.TraceState) == 0 {
+		headers.Del(traceStateHeader)
+	}
+}
+
+func proxy(args []string, stdin io.Reader, stdout io.Writer) error {
+	// Unlike the other commands, proxy has no -json flag, as it always records JSON lines.
+	fs := flag.NewFlagSet("proxy", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	listen := fs.String("listen", ":8080", "address to listen on")
+	target := fs.String("target", "", "URL of the service to forward requests to")
+	logPath := fs.String("log", "-", "JSONL file to append each hop to; - for stdout")
+	repair := fs.Bool("repair", false, "repair invalid trace context where possible")
+	restart := fs.Bool("restart", false, "restart the trace if the traceparent is invalid and cannot be repaired")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *target == "" || fs.NArg() != 0 {
+		return errUsage
+	}
+
+	u, err := url.Parse(*target)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid target URL %q", *target)
+	}
+
+	log := stdout
+	if *logPath != "-" {
+		f, err := os.OpenFile(*logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return errInvalid{err}
+		}
+		defer f.Close()
+		log = f
+	}
+
+	return errInvalid{http.ListenAndServe(*listen, newRecordingProxy(u, log, *repair, *restart))}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("proxy", func() {
+	var (
+		upstream *httptest.Server
+		received []http.Header
+		log      bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		received = nil
+		log.Reset()
+		upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			received = append(received, req.Header)
+			w.WriteHeader(http.StatusTeapot)
+		}))
+	})
+
+	AfterEach(func() {
+		upstream.Close()
+	})
+
+	send := func(repair, restart bool, headers http.Header) hop {
+		target, err := url.Parse(upstream.URL)
+		Expect(err).NotTo(HaveOccurred())
+		server := httptest.NewServer(newRecordingProxy(target, &log, repair, restart))
+		defer server.Close()
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/path", nil)
+		Expect(err).NotTo(HaveOccurred())
+		for k, values := range headers {
+			for _, v := range values {
+				req.Header.Add(k, v)
+			}
+		}
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusTeapot))
+
+		lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+		var h hop
+		Expect(json.Unmarshal([]byte(lines[len(lines)-1]), &h)).To(Succeed())
+		return h
+	}
+
+	It("records and forwards valid trace context unchanged", func() {
+		h := send(true, true, http.Header{"Traceparent": {traceParent}, "Tracestate": {traceState}})
+		Expect(h.Method).To(Equal(http.MethodGet))
+		Expect(h.URL).To(Equal("/path"))
+		Expect(h.Action).To(Equal(actionForwarded))
+		Expect(h.Errors).To(BeEmpty())
+		Expect(h.Before).To(Equal(headerValues{TraceParent: []string{traceParent}, TraceState: []string{traceState}}))
+		Expect(h.After).To(Equal(h.Before))
+		Expect(received[0].Get("traceparent")).To(Equal(traceParent))
+	})
+
+	It("records parse errors and forwards invalid trace context unchanged by default", func() {
+		h := send(false, false, http.Header{"Traceparent": {strings.ToUpper(traceParent)}, "Tracestate": {"a=1,a=2"}})
+		Expect(h.Action).To(Equal(actionForwarded))
+		Expect(h.Errors).To(Equal([]string{
+			"traceparent: tracecontext: Invalid traceparent format",
+			"tracestate: tracecontext: Duplicate list member key in tracestate",
+		}))
+		Expect(h.After).To(Equal(h.Before))
+		Expect(received[0].Get("traceparent")).To(Equal(strings.ToUpper(traceParent)))
+	})
+
+	It("repairs invalid trace context where possible", func() {
+		h := send(true, false, http.Header{"Traceparent": {" " + strings.ToUpper(traceParent), traceParent}, "Tracestate": {"a=1,a=2"}})
+		Expect(h.Action).To(Equal(actionRepaired))
+		Expect(h.After).To(Equal(headerValues{TraceParent: []string{traceParent}}))
+		Expect(received[0]["Traceparent"]).To(Equal([]string{traceParent}))
+		Expect(received[0]).NotTo(HaveKey("Tracestate"))
+	})
+
+	It("restarts the trace if the traceparent cannot be repaired", func() {
+		h := send(true, true, http.Header{"Traceparent": {"00-00000000000000000000000000000000-b7ad6b7169203331-01"}, "Tracestate": {traceState}})
+		Expect(h.Action).To(Equal(actionRestarted))
+		Expect(h.After.TraceState).To(BeEmpty())
+		Expect(h.After.TraceParent).To(HaveLen(1))
+
+		tp, err := traceparent.ParseString(h.After.TraceParent[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tp.Flags.Random).To(BeTrue())
+		Expect(received[0].Get("traceparent")).To(Equal(h.After.TraceParent[0]))
+	})
+
+	It("ignores requests without trace context", func() {
+		h := send(true, true, nil)
+		Expect(h.Action).To(Equal(actionForwarded))
+		Expect(h.After).To(Equal(headerValues{}))
+	})
+})
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/lightstep/tracecontext.go/logscan"
+)
+
+func scan(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs, asJSON := newFlagSet("scan")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	report := logscan.NewReport()
+	if fs.NArg() == 0 {
+		if err := report.Scan(stdin); err != nil {
+			return err
+		}
+	}
+	for _, name := range fs.Args() {
+		if err := scanFile(report, name); err != nil {
+			return errInvalid{err}
+		}
+	}
+
+	if *asJSON {
+		return writeJSON(stdout, report)
+	}
+
+	fmt.Fprintf(stdout, "%d lines, %d traces\n", report.Lines, len(report.Traces))
+	for _, t := range report.Traces {
+		fmt.Fprintf(stdout, "%s: %d occurrences, %d spans\n", t.TraceID, t.Count, len(t.SpanIDs))
+		for _, spanID := range t.SpanIDs {
+			fmt.Fprintf(stdout, "  span %s\n", spanID)
+		}
+		for _, ts := range t.TraceStates {
+			fmt.Fprintf(stdout, "  tracestate %s\n", ts)
+		}
+	}
+	writeCounts(stdout, "invalid traceparent", report.InvalidTraceParents)
+	writeCounts(stdout, "invalid tracestate", report.InvalidTraceStates)
+	return nil
+}
+
+func scanFile(report *logscan.Report, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return report.Scan(f)
+}
+
+// writeCounts prints the total of the counts, followed by each count in descending order.
+func writeCounts(w io.Writer, label string, counts map[string]int) {
+	var total int
+	var keys []string
+	for key, count := range counts {
+		total += count
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	fmt.Fprintf(w, "%s: %d\n", label, total)
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %d %s\n", counts[key], key)
+	}
+}
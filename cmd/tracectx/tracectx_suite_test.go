@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	tracecontext "github.com/lightstep/tracecontext.go"
+	"github.com/lightstep/tracecontext.go/traceparent"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestTracectx(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tracectx Suite")
+}
+
+const (
+	traceParent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	traceState  = "congo=t61rcWkgMzE,rojo@tenant=00f067aa0ba902b7"
+)
+
+func execute(args ...string) (code int, stdout, stderr string) {
+	return executeWithInput("", args...)
+}
+
+func executeWithInput(input string, args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(args, strings.NewReader(input), &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+var _ = Describe("tracectx", func() {
+	It("prints usage for unknown commands and missing arguments", func() {
+		for _, args := range [][]string{
+			nil,
+			{"unknown"},
+			{"decode"},
+			{"decode", "-unknown", traceParent},
+			{"new", "extra"},
+			{"convert", "-from", "unknown", traceParent},
+			{"proxy", "-json", "-target", "http://localhost:8080"},
+		} {
+			code, _, stderr := execute(args...)
+			Expect(code).To(Equal(exitUsage), strings.Join(args, " "))
+			Expect(stderr).To(ContainSubstring("usage: tracectx"))
+		}
+	})
+
+	Describe("decode", func() {
+		It("prints the fields, flags and list members", func() {
+			code, stdout, _ := execute("decode", traceParent, traceState)
+			Expect(code).To(Equal(exitOK))
+			Expect(stdout).To(Equal(strings.Join([]string{
+				"version:   00",
+				"trace-id:  0af7651916cd43dd8448eb211c80319c",
+				"parent-id: b7ad6b7169203331",
+				"flags:     01 (sampled)",
+				"tracestate: 2 members",
+				"   1. congo = t61rcWkgMzE",
+				"   2. rojo@tenant = 00f067aa0ba902b7",
+				"",
+			}, "\n")))
+		})
+
+		It("prints JSON", func() {
+			code, stdout, _ := execute("decode", "-json", traceParent, traceState)
+			Expect(code).To(Equal(exitOK))
+
+			var d decoded
+			Expect(json.Unmarshal([]byte(stdout), &d)).To(Succeed())
+			Expect(d.TraceParent.TraceID).To(Equal("0af7651916cd43dd8448eb211c80319c"))
+			Expect(d.Flags).To(Equal(decodedFlags{Sampled: true}))
+			Expect(d.TraceState).To(Equal([]decodedMember{
+				{Key: "congo", SystemID: "congo", Value: "t61rcWkgMzE"},
+				{Key: "rojo@tenant", TenantID: "rojo", SystemID: "tenant", Value: "00f067aa0ba902b7"},
+			}))
+		})
+	})
+
+	Describe("validate", func() {
+		It("succeeds for valid headers", func() {
+			code, stdout, _ := execute("validate", traceParent, traceState)
+			Expect(code).To(Equal(exitOK))
+			Expect(stdout).To(Equal("valid\n"))
+		})
+
+		It("fails with the reason for invalid headers", func() {
+			code, _, stderr := execute("validate", "00-00000000000000000000000000000000-b7ad6b7169203331-01")
+			Expect(code).To(Equal(exitInvalid))
+			Expect(stderr).To(Equal("tracectx: invalid traceparent: tracecontext: Invalid traceparent trace ID\n"))
+
+			code, stdout, _ := execute("validate", "-json", traceParent, "a=1,a=2")
+			Expect(code).To(Equal(exitInvalid))
+			var v validated
+			Expect(json.Unmarshal([]byte(stdout), &v)).To(Succeed())
+			Expect(v).To(Equal(validated{Error: "invalid tracestate: tracecontext: Duplicate list member key in tracestate"}))
+		})
+	})
+
+	Describe("new", func() {
+		It("generates a valid traceparent with the requested flags", func() {
+			code, stdout, _ := execute("new", "-json", "-sampled=false")
+			Expect(code).To(Equal(exitOK))
+
+			var tc tracecontext.TraceContext
+			Expect(json.Unmarshal([]byte(stdout), &tc)).To(Succeed())
+			Expect(tc.TraceParent.Flags).To(Equal(traceparent.Flags{Random: true}))
+
+			code, stdout, _ = execute("new")
+			Expect(code).To(Equal(exitOK))
+			Expect(stdout).To(MatchRegexp(`^traceparent: 00-[a-f0-9]{32}-[a-f0-9]{16}-03\n$`))
+		})
+	})
+
+	Describe("child", func() {
+		It("derives a child span in the same trace", func() {
+			code, stdout, _ := execute("child", "-json", "-unsampled", traceParent, traceState)
+			Expect(code).To(Equal(exitOK))
+
+			var tc tracecontext.TraceContext
+			Expect(json.Unmarshal([]byte(stdout), &tc)).To(Succeed())
+			Expect(tc.TraceParent.TraceID.String()).To(Equal("0af7651916cd43dd8448eb211c80319c"))
+			Expect(tc.TraceParent.SpanID.String()).NotTo(Equal("b7ad6b7169203331"))
+			Expect(tc.TraceParent.Flags.Recorded).To(BeFalse())
+			Expect(tc.TraceState.String()).To(Equal(traceState))
+		})
+
+		It("sets the sampled flag, or inherits it by default", func() {
+			unsampled := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00"
+
+			code, stdout, _ := execute("child", "-sampled", unsampled)
+			Expect(code).To(Equal(exitOK))
+			Expect(stdout).To(MatchRegexp(`^traceparent: 00-0af7651916cd43dd8448eb211c80319c-[a-f0-9]{16}-01\n$`))
+
+			code, stdout, _ = execute("child", unsampled)
+			Expect(code).To(Equal(exitOK))
+			Expect(stdout).To(MatchRegexp(`^traceparent: 00-0af7651916cd43dd8448eb211c80319c-[a-f0-9]{16}-00\n$`))
+
+			code, _, stderr := execute("child", "-sampled", "-unsampled", unsampled)
+			Expect(code).To(Equal(exitUsage))
+			Expect(stderr).To(HavePrefix("tracectx: -sampled and -unsampled cannot be used together\n"))
+		})
+	})
+
+	Describe("convert", func() {
+		It("converts between formats", func() {
+			code, stdout, _ := execute("convert", "-to", "b3", traceParent)
+			Expect(code).To(Equal(exitOK))
+			Expect(stdout).To(Equal("b3: 0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-1\n"))
+
+			code, stdout, _ = execute("convert", "-from", "sentry", "0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331")
+			Expect(code).To(Equal(exitOK))
+			Expect(stdout).To(Equal("traceparent: 00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00\n"))
+
+			code, stdout, _ = execute("convert", "-json", "-from", "jaeger", "-to", "xray", "af7651916cd43dd8448eb211c80319c:b7ad6b7169203331:0:1")
+			Expect(code).To(Equal(exitOK))
+			var c converted
+			Expect(json.Unmarshal([]byte(stdout), &c)).To(Succeed())
+			Expect(c).To(Equal(converted{
+				Format: "xray",
+				Header: "X-Amzn-Trace-Id",
+				Value:  "Root=1-0af76519-16cd43dd8448eb211c80319c;Parent=b7ad6b7169203331;Sampled=1",
+			}))
+		})
+
+		It("fails for invalid values", func() {
+			code, _, stderr := execute("convert", "-from", "b3", "-to", "w3c", "invalid")
+			Expect(code).To(Equal(exitInvalid))
+			Expect(stderr).To(Equal("tracectx: invalid b3 value: tracecontext: Invalid b3 header\n"))
+		})
+	})
+	Describe("scan", func() {
+		It("groups the trace context found in the input", func() {
+			code, stdout, _ := executeWithInput(strings.Join([]string{
+				"traceparent: " + traceParent + " tracestate: " + traceState,
+				`{"traceparent":"00-0af7651916cd43dd8448eb211c80319c-00f067aa0ba902b7-01"}`,
+				"traceparent: 00-00000000000000000000000000000000-b7ad6b7169203331-01",
+			}, "\n"), "scan")
+			Expect(code).To(Equal(exitOK))
+			Expect(stdout).To(Equal(strings.Join([]string{
+				"3 lines, 1 traces",
+				"0af7651916cd43dd8448eb211c80319c: 2 occurrences, 2 spans",
+				"  span b7ad6b7169203331",
+				"  span 00f067aa0ba902b7",
+				"  tracestate " + traceState,
+				"invalid traceparent: 1",
+				"  1 tracecontext: Invalid traceparent trace ID",
+				"invalid tracestate: 0",
+				"",
+			}, "\n")))
+		})
+
+		It("fails for missing files", func() {
+			code, _, stderr := execute("scan", "/nonexistent")
+			Expect(code).To(Equal(exitInvalid))
+			Expect(stderr).To(ContainSubstring("/nonexistent"))
+		})
+	})
+})
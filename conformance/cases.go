@@ -0,0 +1,408 @@
+package conformance
+
+import (
+	"fmt"
+	"net/textproto"
+	"strings"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+)
+
+const (
+	// SectionTraceParent covers the `traceparent` header.
+	SectionTraceParent = "3.2 Traceparent Header"
+	// SectionTraceState covers the `tracestate` header.
+	SectionTraceState = "3.3 Tracestate Header"
+	// SectionProcessing covers how the two headers are processed together.
+	SectionProcessing = "4 Processing Model"
+)
+
+const (
+	sentTraceID     = "12345678901234567890123456789012"
+	sentSpanID      = "1234567890123456"
+	sentTraceParent = "00-" + sentTraceID + "-" + sentSpanID + "-01"
+)
+
+// Cases returns the conformance cases ported from the W3C trace-context test suite.
+func Cases() []Case {
+	var cases []Case
+	cases = append(cases, traceParentCases()...)
+	cases = append(cases, traceStateCases()...)
+	cases = append(cases, processingCases()...)
+	return cases
+}
+
+func traceParentCases() []Case {
+	cases := []Case{
+		{
+			Section: SectionTraceParent,
+			Name:    "propagates a valid traceparent to a new span",
+			Headers: []Header{{"traceparent", sentTraceParent}},
+			Check:   expectPropagated,
+		},
+		{
+			Section: SectionTraceParent,
+			Name:    "starts a new trace without a traceparent",
+			Check:   expectRestarted,
+		},
+		{
+			Section: SectionTraceParent,
+			Name:    "restarts the trace for duplicated traceparent headers",
+			Headers: []Header{
+				{"traceparent", sentTraceParent},
+				{"traceparent", "00-12345678901234567890123456789011-1234567890123456-01"},
+			},
+			Check: expectRestarted,
+		},
+		{
+			Section: SectionTraceParent,
+			Name:    "accepts future versions with extra fields",
+			Headers: []Header{{"traceparent", "cc-" + sentTraceID + "-" + sentSpanID + "-01-what-the-future-will-be-like"}},
+			Check:   expectPropagated,
+		},
+		{
+			Section: SectionTraceParent,
+			Name:    "accepts future versions without extra fields",
+			Headers: []Header{{"traceparent", "cc-" + sentTraceID + "-" + sentSpanID + "-01"}},
+			Check:   expectPropagated,
+		},
+	}
+
+	for _, name := range []string{"traceparent", "Traceparent", "TraceParent", "TRACEPARENT"} {
+		cases = append(cases, Case{
+			Section: SectionTraceParent,
+			Name:    fmt.Sprintf("treats the header name %q case-insensitively", name),
+			Headers: []Header{{name, sentTraceParent}},
+			Check:   expectPropagated,
+		})
+	}
+
+	for _, value := range []string{" " + sentTraceParent, "\t" + sentTraceParent, sentTraceParent + " ", sentTraceParent + "\t", " \t" + sentTraceParent + " \t"} {
+		cases = append(cases, Case{
+			Section: SectionTraceParent,
+			Name:    fmt.Sprintf("ignores optional whitespace in %q", value),
+			Headers: []Header{{"traceparent", value}},
+			Check:   expectPropagated,
+		})
+	}
+
+	for _, invalid := range []struct{ reason, value string }{
+		{"version 00 with extra fields", sentTraceParent + "-what-the-future-will-be-like"},
+		{"version 00 with a trailing character", sentTraceParent + "."},
+		{"future version with a trailing character", "cc-" + sentTraceID + "-" + sentSpanID + "-01."},
+		{"version ff", "ff-" + sentTraceID + "-" + sentSpanID + "-01"},
+		{"illegal characters in the version", ".0-" + sentTraceID + "-" + sentSpanID + "-01"},
+		{"illegal characters in the version", "0.-" + sentTraceID + "-" + sentSpanID + "-01"},
+		{"uppercase version", "0A-" + sentTraceID + "-" + sentSpanID + "-01"},
+		{"too long version", "000-" + sentTraceID + "-" + sentSpanID + "-01"},
+		{"too short version", "0-" + sentTraceID + "-" + sentSpanID + "-01"},
+		{"all-zero trace ID", "00-00000000000000000000000000000000-" + sentSpanID + "-01"},
+		{"illegal characters in the trace ID", "00-.2345678901234567890123456789012-" + sentSpanID + "-01"},
+		{"illegal characters in the trace ID", "00-1234567890123456789012345678901.-" + sentSpanID + "-01"},
+		{"uppercase trace ID", "00-ABCDEF78901234567890123456789012-" + sentSpanID + "-01"},
+		{"too long trace ID", "00-" + sentTraceID + "3-" + sentSpanID + "-01"},
+		{"too short trace ID", "00-1234567890123456789012345678901-" + sentSpanID + "-01"},
+		{"all-zero parent ID", "00-" + sentTraceID + "-0000000000000000-01"},
+		{"illegal characters in the parent ID", "00-" + sentTraceID + "-.234567890123456-01"},
+		{"illegal characters in the parent ID", "00-" + sentTraceID + "-123456789012345.-01"},
+		{"too long parent ID", "00-" + sentTraceID + "-12345678901234567-01"},
+		{"too short parent ID", "00-" + sentTraceID + "-123456789012345-01"},
+		{"illegal characters in the trace flags", "00-" + sentTraceID + "-" + sentSpanID + "-.0"},
+		{"illegal characters in the trace flags", "00-" + sentTraceID + "-" + sentSpanID + "-0."},
+		{"too long trace flags", "00-" + sentTraceID + "-" + sentSpanID + "-001"},
+		{"too short trace flags", "00-" + sentTraceID + "-" + sentSpanID + "-0"},
+	} {
+		cases = append(cases, Case{
+			Section: SectionTraceParent,
+			Name:    fmt.Sprintf("restarts the trace for %s: %q", invalid.reason, invalid.value),
+			Headers: []Header{{"traceparent", invalid.value}},
+			Check:   expectRestarted,
+		})
+	}
+
+	return cases
+}
+
+func traceStateCases() []Case {
+	cases := []Case{
+		{
+			Section: SectionTraceState,
+			Name:    "propagates tracestate list members",
+			Headers: []Header{{"traceparent", sentTraceParent}, {"tracestate", "foo=1,bar=2"}},
+			Check:   all(expectPropagated, expectMembers("foo=1", "bar=2")),
+		},
+		{
+			Section: SectionTraceState,
+			Name:    "propagates the traceparent with an empty tracestate",
+			Headers: []Header{{"traceparent", sentTraceParent}, {"tracestate", ""}},
+			Check:   expectPropagated,
+		},
+		{
+			Section: SectionTraceState,
+			Name:    "combines multiple tracestate headers in order",
+			Headers: []Header{
+				{"traceparent", sentTraceParent},
+				{"tracestate", "foo=1,bar=2"},
+				{"tracestate", "rojo=1,congo=2"},
+				{"tracestate", "baz=3"},
+			},
+			Check: all(expectPropagated, expectMembers("foo=1", "bar=2", "rojo=1", "congo=2", "baz=3")),
+		},
+		{
+			Section: SectionTraceState,
+			Name:    "ignores optional whitespace around list members",
+			Headers: []Header{{"traceparent", sentTraceParent}, {"tracestate", "foo=1 \t , \t bar=2, \t baz=3"}},
+			Check:   all(expectPropagated, expectMembers("foo=1", "bar=2", "baz=3")),
+		},
+		{
+			Section: SectionTraceState,
+			Name:    "propagates keys and values with all allowed characters",
+			Headers: []Header{
+				{"traceparent", sentTraceParent},
+				{"tracestate", allowedKey + "=" + allowedValue + "," + allowedTenantID + "@" + allowedSystemID + "=1"},
+			},
+			Check: all(expectPropagated, expectMembers(allowedKey+"="+allowedValue, allowedTenantID+"@"+allowedSystemID+"=1")),
+		},
+		{
+			Section: SectionTraceState,
+			Name:    "propagates up to 32 list members",
+			Headers: []Header{{"traceparent", sentTraceParent}, {"tracestate", members(32)}},
+			// The endpoint may drop the last member to make room for its own.
+			Check: all(expectPropagated, expectMembers("k0=0", "k30=30")),
+		},
+		{
+			Section: SectionTraceState,
+			Name:    "propagates the maximum key lengths",
+			Headers: []Header{
+				{"traceparent", sentTraceParent},
+				{"tracestate", "foo=1," + strings.Repeat("z", 256) + "=1," + strings.Repeat("t", 241) + "@" + strings.Repeat("v", 14) + "=1"},
+			},
+			Check: all(expectPropagated, expectMembers("foo=1", strings.Repeat("z", 256)+"=1", strings.Repeat("t", 241)+"@"+strings.Repeat("v", 14)+"=1")),
+		},
+	}
+
+	for _, name := range []string{"tracestate", "Tracestate", "TraceState", "TRACESTATE"} {
+		cases = append(cases, Case{
+			Section: SectionTraceState,
+			Name:    fmt.Sprintf("treats the header name %q case-insensitively", name),
+			Headers: []Header{{"traceparent", sentTraceParent}, {name, "foo=1"}},
+			Check:   all(expectPropagated, expectMembers("foo=1")),
+		})
+	}
+
+	for _, invalid := range []struct {
+		reason  string
+		headers []string
+	}{
+		{"duplicated keys", []string{"foo=1,foo=1"}},
+		{"duplicated keys", []string{"foo=1,foo=2"}},
+		{"duplicated keys across headers", []string{"foo=1", "foo=1"}},
+		{"illegal characters in a key", []string{"foo=1,bar @=2"}},
+		{"illegal characters in a key", []string{"foo=1,bar =2"}},
+		{"illegal characters in a key", []string{"foo=1,BAR=2"}},
+		{"illegal characters in a key", []string{"foo=1,bar.baz=2"}},
+		{"an invalid multi-tenant key", []string{"foo=1,bar@=2"}},
+		{"an invalid multi-tenant key", []string{"foo=1,@bar=2"}},
+		{"an invalid multi-tenant key", []string{"foo=1,bar@@baz=2"}},
+		{"an invalid multi-tenant key", []string{"foo=1,bar@baz@qux=2"}},
+		{"a too long key", []string{"foo=1," + strings.Repeat("z", 257) + "=1"}},
+		{"a too long tenant ID", []string{"foo=1," + strings.Repeat("t", 242) + "@v=1"}},
+		{"a too long system ID", []string{"foo=1,t@" + strings.Repeat("v", 15) + "=1"}},
+		{"illegal characters in a value", []string{"foo=bar=baz"}},
+		{"an empty value", []string{"foo=,bar=3"}},
+		{"more than 32 list members", []string{members(33)}},
+	} {
+		headers := []Header{{"traceparent", sentTraceParent}}
+		for _, value := range invalid.headers {
+			headers = append(headers, Header{"tracestate", value})
+		}
+
+		cases = append(cases, Case{
+			Section: SectionTraceState,
+			Name:    fmt.Sprintf("discards the tracestate for %s: %q", invalid.reason, strings.Join(invalid.headers, ", ")),
+			Headers: headers,
+			Check:   all(expectPropagated, expectNoMembers("foo", "bar", "k0")),
+		})
+	}
+
+	return cases
+}
+
+func processingCases() []Case {
+	return []Case{
+		{
+			Section: SectionProcessing,
+			Name:    "ignores the tracestate without a traceparent",
+			Headers: []Header{{"tracestate", "foo=1"}},
+			Check:   all(expectRestarted, expectNoMembers("foo")),
+		},
+		{
+			Section: SectionProcessing,
+			Name:    "discards the tracestate if the traceparent is invalid",
+			Headers: []Header{{"traceparent", "00-00000000000000000000000000000000-" + sentSpanID + "-01"}, {"tracestate", "foo=1"}},
+			Check:   all(expectRestarted, expectNoMembers("foo")),
+		},
+		{
+			Section:   SectionProcessing,
+			Name:      "uses a new parent ID for each outgoing request in the same trace",
+			Headers:   []Header{{"traceparent", sentTraceParent}},
+			Callbacks: 10,
+			Check:     all(expectPropagated, expectDistinct(func(tp traceparent.TraceParent) string { return tp.SpanID.String() })),
+		},
+		{
+			Section:  SectionProcessing,
+			Name:     "starts a different trace for each request without a traceparent",
+			Requests: 10,
+			Check:    all(expectRestarted, expectDistinct(func(tp traceparent.TraceParent) string { return tp.TraceID.String() })),
+		},
+		{
+			Section:  SectionProcessing,
+			Name:     "starts a different trace for each request with an invalid traceparent",
+			Headers:  []Header{{"traceparent", "00-" + sentTraceID + "-" + sentSpanID + "-0"}},
+			Requests: 10,
+			Check:    all(expectRestarted, expectDistinct(func(tp traceparent.TraceParent) string { return tp.TraceID.String() })),
+		},
+	}
+}
+
+const (
+	allowedKey      = "abcdefghijklmnopqrstuvwxyz0123456789_-*/"
+	allowedTenantID = allowedKey
+	allowedSystemID = "system0_-*/"
+	allowedValue    = "!\"#$%&'()*+-./0123456789:;<>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~ ~"
+)
+
+func members(n int) string {
+	var m []string
+	for i := 0; i < n; i++ {
+		m = append(m, fmt.Sprintf("k%d=%d", i, i))
+	}
+	return strings.Join(m, ",")
+}
+
+func all(checks ...func([]Callback) error) func([]Callback) error {
+	return func(callbacks []Callback) error {
+		for _, check := range checks {
+			if err := check(callbacks); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// receivedTraceParent returns the single, valid `traceparent` the endpoint sent in a callback.
+func receivedTraceParent(c Callback) (traceparent.TraceParent, error) {
+	values := c.Header[textproto.CanonicalMIMEHeaderKey("traceparent")]
+	if len(values) != 1 {
+		return traceparent.TraceParent{}, fmt.Errorf("expected exactly 1 traceparent header, got %d", len(values))
+	}
+
+	tp, err := traceparent.ParseString(values[0])
+	if err != nil {
+		return tp, fmt.Errorf("sent invalid traceparent %q: %s", values[0], err)
+	}
+	if tp.Version != 0 {
+		return tp, fmt.Errorf("sent traceparent %q with unsupported version", values[0])
+	}
+	return tp, nil
+}
+
+// receivedTraceState returns the `tracestate` the endpoint sent in a callback, combining multiple headers.
+func receivedTraceState(c Callback) (tracestate.TraceState, error) {
+	values := c.Header[textproto.CanonicalMIMEHeaderKey("tracestate")]
+	ts, err := tracestate.ParseString(strings.Join(values, ","))
+	if err != nil {
+		return ts, fmt.Errorf("sent invalid tracestate %q: %s", values, err)
+	}
+	return ts, nil
+}
+
+func expectPropagated(callbacks []Callback) error {
+	for _, c := range callbacks {
+		tp, err := receivedTraceParent(c)
+		if err != nil {
+			return err
+		}
+		if tp.TraceID.String() != sentTraceID {
+			return fmt.Errorf("expected trace ID %s to be propagated, got %s", sentTraceID, tp.TraceID)
+		}
+		if tp.SpanID.String() == sentSpanID {
+			return fmt.Errorf("expected a new parent ID, got the received parent ID %s", tp.SpanID)
+		}
+	}
+	return nil
+}
+
+func expectRestarted(callbacks []Callback) error {
+	for _, c := range callbacks {
+		tp, err := receivedTraceParent(c)
+		if err != nil {
+			return err
+		}
+		if tp.TraceID.String() == sentTraceID {
+			return fmt.Errorf("expected a new trace ID, got the received trace ID %s", tp.TraceID)
+		}
+	}
+	return nil
+}
+
+// expectMembers checks that the given list members were propagated in the given order,
+// allowing the endpoint to add or update its own members.
+func expectMembers(expected ...string) func([]Callback) error {
+	return func(callbacks []Callback) error {
+		for _, c := range callbacks {
+			ts, err := receivedTraceState(c)
+			if err != nil {
+				return err
+			}
+
+			next := 0
+			for _, member := range ts {
+				if next < len(expected) && member.String() == expected[next] {
+					next++
+				}
+			}
+			if next < len(expected) {
+				return fmt.Errorf("expected tracestate to contain %q in order, got %q", expected, ts.String())
+			}
+		}
+		return nil
+	}
+}
+
+// expectNoMembers checks that no list members with the given keys were propagated.
+func expectNoMembers(keys ...string) func([]Callback) error {
+	return func(callbacks []Callback) error {
+		for _, c := range callbacks {
+			ts, err := receivedTraceState(c)
+			if err != nil {
+				return err
+			}
+			for _, key := range keys {
+				if _, ok := ts.GetKey(key); ok {
+					return fmt.Errorf("expected tracestate not to contain key %q, got %q", key, ts.String())
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func expectDistinct(id func(traceparent.TraceParent) string) func([]Callback) error {
+	return func(callbacks []Callback) error {
+		seen := make(map[string]bool)
+		for _, c := range callbacks {
+			tp, err := receivedTraceParent(c)
+			if err != nil {
+				return err
+			}
+			if seen[id(tp)] {
+				return fmt.Errorf("expected distinct IDs, got %s more than once", id(tp))
+			}
+			seen[id(tp)] = true
+		}
+		return nil
+	}
+}
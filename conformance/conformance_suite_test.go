@@ -0,0 +1,95 @@
+package conformance_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tracecontext "github.com/lightstep/tracecontext.go"
+	. "github.com/lightstep/tracecontext.go/conformance"
+	"github.com/lightstep/tracecontext.go/traceparent"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestConformance(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Conformance Suite")
+}
+
+// endpoint returns a minimal endpoint under test that makes each callback with the trace context produced by mutate,
+// which is passed a number that is unique to the callback.
+func endpoint(mutate func(in, out http.Header, n byte)) *httptest.Server {
+	var n byte
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body []struct {
+			URL string `json:"url"`
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+
+		for _, item := range body {
+			n++
+			r, _ := http.NewRequest(http.MethodPost, item.URL, strings.NewReader("[]"))
+			mutate(req.Header, r.Header, n)
+			if r.Header.Get("traceparent") == "" {
+				tp := traceparent.TraceParent{TraceID: traceparent.TraceID{0: n}, SpanID: traceparent.SpanID{0: n}}
+				r.Header.Set("traceparent", tp.String())
+			}
+			if resp, err := http.DefaultClient.Do(r); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}))
+}
+
+var _ = Describe("Runner", func() {
+	It("passes an endpoint that propagates the trace context correctly", func() {
+		server := endpoint(func(in, out http.Header, n byte) {
+			tc, err := tracecontext.FromHeaders(in)
+			if err != nil {
+				return
+			}
+			tc.TraceParent.Version = traceparent.Version
+			tc.TraceParent.SpanID = traceparent.SpanID{0: n}
+			tc.SetHeaders(out)
+		})
+		defer server.Close()
+
+		report := Run(server.URL)
+		Expect(report.Passed()).To(BeTrue(), report.String())
+
+		sections := report.Sections()
+		Expect(sections).To(HaveLen(3))
+		Expect(sections[0].Section).To(Equal(SectionTraceParent))
+		Expect(sections[1].Section).To(Equal(SectionTraceState))
+		Expect(sections[2].Section).To(Equal(SectionProcessing))
+	})
+
+	It("reports failures per section for an endpoint that forwards headers unchanged", func() {
+		server := endpoint(func(in, out http.Header, n byte) {
+			for _, key := range []string{"Traceparent", "Tracestate"} {
+				out[key] = in[key]
+			}
+		})
+		defer server.Close()
+
+		report := Run(server.URL)
+		Expect(report.Passed()).To(BeFalse())
+		Expect(report.Failures()).NotTo(BeEmpty())
+		for _, section := range report.Sections() {
+			Expect(section.Failed).To(BeNumerically(">", 0), section.Section)
+		}
+		Expect(report.String()).To(ContainSubstring("FAIL [3.2 Traceparent Header] propagates a valid traceparent to a new span: expected a new parent ID"))
+	})
+
+	It("fails cases for which the endpoint makes no callbacks", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+		defer server.Close()
+
+		report := Runner{Timeout: 1}.Run(server.URL, Cases()[:1])
+		Expect(report.Results).To(HaveLen(1))
+		Expect(report.Results[0].Err).To(MatchError("expected 1 callbacks, got 0"))
+	})
+})
@@ -0,0 +1,245 @@
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+const callbackPath = "/callback/"
+
+// Header is a single request header, sent with its name exactly as given so that casing can be tested.
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Callback is a request that the endpoint under test made to a callback URL.
+type Callback struct {
+	Header http.Header
+}
+
+// Case is a single conformance test case.
+type Case struct {
+	// Section is the section of the spec that the case covers.
+	Section string
+	// Name describes the behaviour under test.
+	Name string
+	// Headers are sent with each request to the endpoint under test.
+	Headers []Header
+	// Requests is the number of requests to send to the endpoint under test. It defaults to 1.
+	Requests int
+	// Callbacks is the number of callbacks each request asks the endpoint to make. It defaults to 1.
+	Callbacks int
+	// Check verifies the callbacks the endpoint made, returning an error describing any non-conformance.
+	Check func(callbacks []Callback) error
+}
+
+// Result is the outcome of running a single `Case`.
+type Result struct {
+	Case Case
+	// Err is nil if the case passed.
+	Err error
+}
+
+// SectionReport summarizes the results of the cases that cover a single section of the spec.
+type SectionReport struct {
+	Section string
+	Passed  int
+	Failed  int
+}
+
+// Report holds the results of a conformance run.
+type Report struct {
+	Results []Result
+}
+
+// Passed reports whether every case passed.
+func (r Report) Passed() bool {
+	return len(r.Failures()) == 0
+}
+
+// Failures returns the results of the cases that failed.
+func (r Report) Failures() []Result {
+	var failures []Result
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// Sections summarizes the results per section of the spec, in the order in which the sections were first run.
+func (r Report) Sections() []SectionReport {
+	var sections []SectionReport
+	index := make(map[string]int)
+	for _, result := range r.Results {
+		i, ok := index[result.Case.Section]
+		if !ok {
+			i = len(sections)
+			index[result.Case.Section] = i
+			sections = append(sections, SectionReport{Section: result.Case.Section})
+		}
+		if result.Err == nil {
+			sections[i].Passed++
+		} else {
+			sections[i].Failed++
+		}
+	}
+	return sections
+}
+
+// String formats the report with a line per case, followed by a summary per section.
+func (r Report) String() string {
+	var b strings.Builder
+	for _, result := range r.Results {
+		if result.Err == nil {
+			fmt.Fprintf(&b, "PASS [%s] %s\n", result.Case.Section, result.Case.Name)
+		} else {
+			fmt.Fprintf(&b, "FAIL [%s] %s: %s\n", result.Case.Section, result.Case.Name, result.Err)
+		}
+	}
+	for _, section := range r.Sections() {
+		fmt.Fprintf(&b, "%s: %d passed, %d failed\n", section.Section, section.Passed, section.Failed)
+	}
+	return b.String()
+}
+
+// Runner drives an endpoint under test through conformance cases, using the callback protocol of the W3C test harness:
+// each request's body is a JSON array of `{"url": ..., "arguments": [...]}` objects, and the endpoint is expected to
+// POST the arguments to each URL, propagating the trace context of the request it received.
+type Runner struct {
+	// Client is used to make requests to the endpoint under test. It defaults to `http.DefaultClient`.
+	Client *http.Client
+	// Timeout limits how long to wait for the callbacks of each case. It defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// Run runs all of the `Cases` against the endpoint at the given URL.
+func Run(target string) Report {
+	return Runner{}.Run(target, Cases())
+}
+
+// Run runs the given cases against the endpoint at the given URL, serving the callbacks from a local server.
+func (r Runner) Run(target string, cases []Case) Report {
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	recorder := newRecorder()
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	var report Report
+	for i, c := range cases {
+		url := fmt.Sprintf("%s%s%d", server.URL, callbackPath, i)
+		err := runCase(client, target, url, c, recorder, timeout)
+		report.Results = append(report.Results, Result{Case: c, Err: err})
+	}
+	return report
+}
+
+func runCase(client *http.Client, target, url string, c Case, recorder *recorder, timeout time.Duration) error {
+	requests, callbacks := c.Requests, c.Callbacks
+	if requests == 0 {
+		requests = 1
+	}
+	if callbacks == 0 {
+		callbacks = 1
+	}
+
+	type item struct {
+		URL       string        `json:"url"`
+		Arguments []interface{} `json:"arguments"`
+	}
+	items := make([]item, callbacks)
+	for i := range items {
+		items[i] = item{URL: url, Arguments: []interface{}{}}
+	}
+	body, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < requests; i++ {
+		req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for _, h := range c.Headers {
+			req.Header[h.Name] = append(req.Header[h.Name], h.Value)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("calling endpoint under test: %s", err)
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	received := recorder.wait(url, requests*callbacks, timeout)
+	if len(received) != requests*callbacks {
+		return fmt.Errorf("expected %d callbacks, got %d", requests*callbacks, len(received))
+	}
+
+	return c.Check(received)
+}
+
+type recorder struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	callbacks map[string][]Callback
+}
+
+func newRecorder() *recorder {
+	r := &recorder{callbacks: make(map[string][]Callback)}
+	r.cond = sync.NewCond(&r.mu)
+	return r
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	io.Copy(ioutil.Discard, req.Body)
+
+	r.mu.Lock()
+	key := req.URL.Path
+	r.callbacks[key] = append(r.callbacks[key], Callback{Header: req.Header})
+	r.mu.Unlock()
+	r.cond.Broadcast()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// wait returns the callbacks made to the URL, waiting until there are at least n of them or the timeout elapses.
+func (r *recorder) wait(url string, n int, timeout time.Duration) []Callback {
+	key := url[strings.Index(url, callbackPath):]
+
+	deadline := time.Now().Add(timeout)
+	timer := time.AfterFunc(timeout, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.cond.Broadcast()
+	})
+	defer timer.Stop()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for len(r.callbacks[key]) < n && time.Now().Before(deadline) {
+		r.cond.Wait()
+	}
+	return append([]Callback(nil), r.callbacks[key]...)
+}
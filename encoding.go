@@ -0,0 +1,113 @@
+package tracecontext
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+)
+
+const textDelimiter = " "
+
+type jsonTraceContext struct {
+	TraceParent traceparent.TraceParent `json:"traceparent"`
+	TraceState  string                  `json:"tracestate,omitempty"`
+}
+
+// MarshalText implements `encoding.TextMarshaler`.
+// The `TraceContext` is encoded as its `traceparent`, followed by a space and its `tracestate` if the latter is not empty.
+func (tc TraceContext) MarshalText() ([]byte, error) {
+	text := tc.TraceParent.String()
+	if len(tc.TraceState) > 0 {
+		text += textDelimiter + tc.TraceState.String()
+	}
+	return []byte(text), nil
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`, decoding the form produced by `MarshalText`.
+// As with `FromHeaders`, it is an error for the `traceparent` to be invalid, but an invalid `tracestate` results in an empty `TraceState`.
+func (tc *TraceContext) UnmarshalText(text []byte) error {
+	traceParent, traceState := text, []byte(nil)
+	if i := bytes.Index(text, []byte(textDelimiter)); i >= 0 {
+		traceParent, traceState = text[:i], text[i+1:]
+	}
+
+	tp, err := traceparent.Parse(traceParent)
+	if err != nil {
+		return err
+	}
+
+	tc.set(tp, traceState)
+	return nil
+}
+
+// MarshalJSON implements `json.Marshaler`, encoding the `TraceContext` as a JSON object with `traceparent` and `tracestate` fields.
+func (tc TraceContext) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonTraceContext{
+		TraceParent: tc.TraceParent,
+		TraceState:  tc.TraceState.String(),
+	})
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`, decoding the form produced by `MarshalJSON`.
+// The `traceparent` field may also use the object form of `traceparent.Object`.
+// As with `FromHeaders`, it is an error for the `traceparent` to be invalid, but an invalid `tracestate` results in an empty `TraceState`.
+func (tc *TraceContext) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	var raw struct {
+		TraceParent json.RawMessage `json:"traceparent"`
+		TraceState  string          `json:"tracestate"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw.TraceParent) == 0 || bytes.Equal(raw.TraceParent, []byte("null")) {
+		return traceparent.ErrInvalidFormat
+	}
+
+	var traceParent traceparent.TraceParent
+	if err := json.Unmarshal(raw.TraceParent, &traceParent); err != nil {
+		return err
+	}
+
+	tc.set(traceParent, []byte(raw.TraceState))
+	return nil
+}
+
+// MarshalBinary implements `encoding.BinaryMarshaler`.
+// The `TraceContext` is encoded as the binary form of its `TraceParent`, followed by its `tracestate`.
+func (tc TraceContext) MarshalBinary() ([]byte, error) {
+	b, err := tc.TraceParent.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(b, tc.TraceState.String()...), nil
+}
+
+// UnmarshalBinary implements `encoding.BinaryUnmarshaler`, decoding the form produced by `MarshalBinary`.
+// As with `FromHeaders`, it is an error for the `traceparent` to be invalid, but an invalid `tracestate` results in an empty `TraceState`.
+func (tc *TraceContext) UnmarshalBinary(data []byte) error {
+	if len(data) < traceparent.BinaryLen {
+		return traceparent.ErrInvalidFormat
+	}
+
+	var traceParent traceparent.TraceParent
+	if err := traceParent.UnmarshalBinary(data[:traceparent.BinaryLen]); err != nil {
+		return err
+	}
+
+	tc.set(traceParent, data[traceparent.BinaryLen:])
+	return nil
+}
+
+func (tc *TraceContext) set(traceParent traceparent.TraceParent, traceState []byte) {
+	tc.TraceParent = traceParent
+	tc.TraceState = nil
+	if ts, err := tracestate.Parse(traceState); err == nil {
+		tc.TraceState = ts
+	}
+}
@@ -0,0 +1,67 @@
+package interop
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+)
+
+const (
+	// B3Header is the name of the header that carries the single-header B3 format.
+	B3Header = "b3"
+)
+
+var (
+	// ErrInvalidB3 occurs when a `b3` header is incorrectly formatted, or carries only a sampling decision.
+	ErrInvalidB3 = errors.New("tracecontext: Invalid b3 header")
+)
+
+// ParseB3 attempts to convert a single-header B3 value, `{TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}`, to a `TraceParent`.
+// The sampling state and parent span ID are optional. A 64-bit trace ID is padded with leading zeroes.
+// The debug sampling state, `d`, is treated as recorded. The parent span ID is discarded.
+func ParseB3(s string) (traceparent.TraceParent, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return traceparent.TraceParent{}, ErrInvalidB3
+	}
+	if len(parts[0]) != 16 && len(parts[0]) != 32 || len(parts[1]) != 16 {
+		return traceparent.TraceParent{}, ErrInvalidB3
+	}
+
+	traceID, ok := parseTraceID(parts[0])
+	if !ok {
+		return traceparent.TraceParent{}, ErrInvalidB3
+	}
+	spanID, ok := parseSpanID(parts[1])
+	if !ok {
+		return traceparent.TraceParent{}, ErrInvalidB3
+	}
+
+	var recorded bool
+	if len(parts) > 2 {
+		switch parts[2] {
+		case "1", "d":
+			recorded = true
+		case "0":
+		default:
+			return traceparent.TraceParent{}, ErrInvalidB3
+		}
+	}
+	if len(parts) > 3 {
+		if _, ok := parseSpanID(parts[3]); !ok || len(parts[3]) != 16 {
+			return traceparent.TraceParent{}, ErrInvalidB3
+		}
+	}
+
+	return newTraceParent(traceID, spanID, recorded), nil
+}
+
+// FormatB3 encodes a `TraceParent` as a single-header B3 value, with a 128-bit trace ID and an explicit sampling state.
+func FormatB3(tp traceparent.TraceParent) string {
+	sampled := "0"
+	if tp.Flags.Recorded {
+		sampled = "1"
+	}
+	return tp.TraceID.String() + "-" + tp.SpanID.String() + "-" + sampled
+}
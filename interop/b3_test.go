@@ -0,0 +1,53 @@
+package interop_test
+
+import (
+	. "github.com/lightstep/tracecontext.go/interop"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("B3", func() {
+	It("parses valid values", func() {
+		for value, expected := range map[string]string{
+			"80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1":                  "00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-01",
+			"80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-d":                  "00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-01",
+			"80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-0-05e3ac9a4f6e3b90": "00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-00",
+			"64fe8b2a57d3eff7-e457b5a2e4d86bd1":                                    "00-000000000000000064fe8b2a57d3eff7-e457b5a2e4d86bd1-00",
+		} {
+			tp, err := ParseB3(value)
+			Expect(err).NotTo(HaveOccurred(), value)
+			Expect(tp.String()).To(Equal(expected), value)
+		}
+	})
+
+	It("errors on invalid values", func() {
+		for _, value := range []string{
+			"",
+			"1",
+			"d",
+			"80f198ee56343ba864fe8b2a57d3eff7",
+			"80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-2",
+			"80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86b",
+			"00000000000000000000000000000000-e457b5a2e4d86bd1-1",
+			"80f198ee56343ba864fe8b2a57d3eff7-0000000000000000-1",
+			"80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3",
+			"80f198ee56343ba864fe8b2a57d3efzz-e457b5a2e4d86bd1-1",
+		} {
+			_, err := ParseB3(value)
+			Expect(err).To(Equal(ErrInvalidB3), value)
+		}
+	})
+
+	It("round-trips through FormatB3", func() {
+		for _, s := range []string{
+			"00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-01",
+			"00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-00",
+		} {
+			value := FormatB3(mustTraceParent(s))
+			tp, err := ParseB3(value)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tp.String()).To(Equal(s))
+		}
+		Expect(FormatB3(mustTraceParent("00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-01"))).To(Equal("80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1"))
+	})
+})
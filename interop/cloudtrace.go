@@ -0,0 +1,80 @@
+package interop
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+)
+
+const (
+	// CloudTraceHeader is the name of the header that carries the Google Cloud trace context.
+	CloudTraceHeader = "X-Cloud-Trace-Context"
+
+	cloudTraceOptionTraced = "o="
+)
+
+var (
+	// ErrInvalidCloudTrace occurs when an `X-Cloud-Trace-Context` header is missing or incorrectly formatted,
+	// e.g., if its decimal span ID does not fit in 64 bits.
+	ErrInvalidCloudTrace = errors.New("tracecontext: Invalid X-Cloud-Trace-Context header")
+)
+
+// ParseCloudTrace attempts to convert a Google Cloud value, `TRACE_ID/SPAN_ID;o=OPTIONS`, to a `TraceParent`.
+// The trace ID is 32 hex characters and the span ID is an unsigned 64-bit decimal integer.
+// The options are optional, and their lowest bit, i.e., `o=1`, is the sampled flag.
+func ParseCloudTrace(s string) (traceparent.TraceParent, error) {
+	s = strings.TrimSpace(s)
+
+	var options string
+	if i := strings.IndexByte(s, ';'); i >= 0 {
+		s, options = s[:i], s[i+1:]
+	}
+
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 || len(parts[0]) != 32 {
+		return traceparent.TraceParent{}, ErrInvalidCloudTrace
+	}
+	traceID, ok := parseTraceID(parts[0])
+	if !ok {
+		return traceparent.TraceParent{}, ErrInvalidCloudTrace
+	}
+
+	// `ParseUint` also rejects values that overflow 64 bits, and signs.
+	span, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || span == 0 {
+		return traceparent.TraceParent{}, ErrInvalidCloudTrace
+	}
+
+	var sampled bool
+	if options != "" {
+		if !strings.HasPrefix(options, cloudTraceOptionTraced) {
+			return traceparent.TraceParent{}, ErrInvalidCloudTrace
+		}
+		o, err := strconv.ParseUint(options[len(cloudTraceOptionTraced):], 10, 64)
+		if err != nil {
+			return traceparent.TraceParent{}, ErrInvalidCloudTrace
+		}
+		sampled = o&1 == 1
+	}
+
+	return newTraceParent(traceID, traceparent.SpanIDFromUint64(span), sampled), nil
+}
+
+// FormatCloudTrace encodes a `TraceParent` as a Google Cloud value, with the span ID in decimal and the sampled flag as `o=1` or `o=0`.
+func FormatCloudTrace(tp traceparent.TraceParent) string {
+	sampled := 0
+	if tp.Flags.Recorded {
+		sampled = 1
+	}
+	return fmt.Sprintf("%s/%d;%s%d", tp.TraceID, tp.SpanID.Uint64(), cloudTraceOptionTraced, sampled)
+}
+
+// CloudTraceFallback converts the `X-Cloud-Trace-Context` header to a `TraceParent`.
+// It may be used as `tracecontext.Options.Fallback`, so that requests from Google load balancers without a `traceparent` header continue their trace.
+func CloudTraceFallback(headers http.Header) (traceparent.TraceParent, error) {
+	return ParseCloudTrace(headers.Get(CloudTraceHeader))
+}
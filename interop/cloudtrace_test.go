@@ -0,0 +1,80 @@
+package interop_test
+
+import (
+	"net/http"
+
+	tracecontext "github.com/lightstep/tracecontext.go"
+	. "github.com/lightstep/tracecontext.go/interop"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("X-Cloud-Trace-Context", func() {
+	It("parses valid values", func() {
+		for value, expected := range map[string]string{
+			"105445aa7843bc8bf206b12000100000/1;o=1":                    "00-105445aa7843bc8bf206b12000100000-0000000000000001-01",
+			"105445aa7843bc8bf206b12000100000/18446744073709551615;o=0": "00-105445aa7843bc8bf206b12000100000-ffffffffffffffff-00",
+			"105445aa7843bc8bf206b12000100000/16473373924128022545":     "00-105445aa7843bc8bf206b12000100000-e49d2e4de7d56011-00",
+			"105445aa7843bc8bf206b12000100000/123;o=3":                  "00-105445aa7843bc8bf206b12000100000-000000000000007b-01",
+		} {
+			tp, err := ParseCloudTrace(value)
+			Expect(err).NotTo(HaveOccurred(), value)
+			Expect(tp.String()).To(Equal(expected), value)
+		}
+	})
+
+	It("errors on invalid values, including span IDs that overflow 64 bits", func() {
+		for _, value := range []string{
+			"",
+			"105445aa7843bc8bf206b12000100000",
+			"105445aa7843bc8bf206b12000100000/",
+			"105445aa7843bc8bf206b12000100000/18446744073709551616",
+			"105445aa7843bc8bf206b12000100000/99999999999999999999999",
+			"105445aa7843bc8bf206b12000100000/-1",
+			"105445aa7843bc8bf206b12000100000/+1",
+			"105445aa7843bc8bf206b12000100000/0",
+			"105445aa7843bc8bf206b12000100000/e49d1b83c1b35011",
+			"105445aa7843bc8bf206b12000100000/1;o=x",
+			"105445aa7843bc8bf206b12000100000/1;x=1",
+			"00000000000000000000000000000000/1;o=1",
+			"105445aa7843bc8bf206b120001000/1;o=1",
+		} {
+			_, err := ParseCloudTrace(value)
+			Expect(err).To(Equal(ErrInvalidCloudTrace), value)
+		}
+	})
+
+	It("round-trips through FormatCloudTrace", func() {
+		for _, s := range []string{
+			"00-105445aa7843bc8bf206b12000100000-ffffffffffffffff-01",
+			"00-105445aa7843bc8bf206b12000100000-0000000000000001-00",
+		} {
+			tp, err := ParseCloudTrace(FormatCloudTrace(mustTraceParent(s)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tp.String()).To(Equal(s))
+		}
+		Expect(FormatCloudTrace(mustTraceParent("00-105445aa7843bc8bf206b12000100000-000000000000007b-01"))).To(Equal("105445aa7843bc8bf206b12000100000/123;o=1"))
+	})
+
+	It("is usable as a fallback when there is no traceparent header", func() {
+		opts := tracecontext.Options{Fallback: CloudTraceFallback}
+
+		headers := http.Header{}
+		headers.Set("X-Cloud-Trace-Context", "105445aa7843bc8bf206b12000100000/123;o=1")
+		tc, err := tracecontext.FromHeadersWithOptions(headers, opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceParent.String()).To(Equal("00-105445aa7843bc8bf206b12000100000-000000000000007b-01"))
+
+		headers.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00")
+		tc, err = tracecontext.FromHeadersWithOptions(headers, opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceParent.String()).To(Equal("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00"))
+
+		headers.Set("traceparent", "invalid")
+		_, err = tracecontext.FromHeadersWithOptions(headers, opts)
+		Expect(err).To(MatchError("tracecontext: Invalid traceparent format"))
+
+		_, err = tracecontext.FromHeadersWithOptions(http.Header{}, opts)
+		Expect(err).To(Equal(ErrInvalidCloudTrace))
+	})
+})
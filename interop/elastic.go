@@ -0,0 +1,86 @@
+package interop
+
+import (
+	"errors"
+	"math"
+	"net/http"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+)
+
+const (
+	// ElasticTraceParentHeader is the name of the header that carries the legacy Elastic APM trace context,
+	// which has the same format as `traceparent`.
+	ElasticTraceParentHeader = "elastic-apm-traceparent"
+	// ElasticTraceStateKey is the key of the Elastic APM `tracestate` list member, e.g., `es=s:0.5`.
+	ElasticTraceStateKey = "es"
+
+	elasticSampleRateField     = "s"
+	elasticSampleRatePrecision = 1e4
+)
+
+var (
+	// ErrInvalidElastic occurs when an `elastic-apm-traceparent` header or an Elastic APM sample rate is incorrectly formatted.
+	ErrInvalidElastic = errors.New("tracecontext: Invalid Elastic APM trace context")
+)
+
+// ParseElasticTraceParent attempts to convert an `elastic-apm-traceparent` value to a `TraceParent`.
+func ParseElasticTraceParent(s string) (traceparent.TraceParent, error) {
+	tp, err := traceparent.ParseString(s)
+	if err != nil {
+		return tp, ErrInvalidElastic
+	}
+	return tp, nil
+}
+
+// ElasticFallback converts the `elastic-apm-traceparent` header to a `TraceParent`.
+// It may be used as `tracecontext.Options.Fallback`, so that requests from older Elastic APM agents continue their trace.
+// Those agents also send a `tracestate` header, which is handled as usual.
+func ElasticFallback(headers http.Header) (traceparent.TraceParent, error) {
+	return ParseElasticTraceParent(headers.Get(ElasticTraceParentHeader))
+}
+
+// ElasticSampleRate returns the sample rate, between 0 and 1, of the `es` list member, and whether there is a valid one.
+func ElasticSampleRate(ts tracestate.TraceState) (float64, bool) {
+	m, ok := ts.GetKey(ElasticTraceStateKey)
+	if !ok {
+		return 0, false
+	}
+	fields, err := tracestate.ParseFields(m.Value)
+	if err != nil {
+		return 0, false
+	}
+	rate, err := fields.GetFloat(elasticSampleRateField)
+	if err != nil || !validSampleRate(rate) {
+		return 0, false
+	}
+	return rate, true
+}
+
+// SetElasticSampleRate returns a copy of the `TraceState` whose `es` list member has the given sample rate, rounded to 4 decimal places
+// as Elastic APM agents require. Other fields of an existing `es` list member are kept if it is valid.
+// It returns `ErrInvalidElastic` if the sample rate is not between 0 and 1.
+func SetElasticSampleRate(ts tracestate.TraceState, rate float64) (tracestate.TraceState, error) {
+	if !validSampleRate(rate) {
+		return ts, ErrInvalidElastic
+	}
+
+	var fields tracestate.Fields
+	if m, ok := ts.GetKey(ElasticTraceStateKey); ok {
+		fields, _ = tracestate.ParseFields(m.Value)
+	}
+	if err := fields.SetFloat(elasticSampleRateField, math.Round(rate*elasticSampleRatePrecision)/elasticSampleRatePrecision); err != nil {
+		return ts, err
+	}
+
+	m, err := tracestate.NewSimpleMember(ElasticTraceStateKey, fields.String())
+	if err != nil {
+		return ts, err
+	}
+	return ts.Set(m)
+}
+
+func validSampleRate(rate float64) bool {
+	return rate >= 0 && rate <= 1
+}
@@ -0,0 +1,61 @@
+package interop_test
+
+import (
+	"net/http"
+
+	tracecontext "github.com/lightstep/tracecontext.go"
+	. "github.com/lightstep/tracecontext.go/interop"
+	"github.com/lightstep/tracecontext.go/tracestate"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Elastic APM", func() {
+	It("parses elastic-apm-traceparent", func() {
+		tp, err := ParseElasticTraceParent("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tp.String()).To(Equal("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"))
+
+		_, err = ParseElasticTraceParent("invalid")
+		Expect(err).To(Equal(ErrInvalidElastic))
+	})
+
+	It("is usable as a fallback when there is no traceparent header", func() {
+		headers := http.Header{}
+		headers.Set("elastic-apm-traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+		headers.Set("tracestate", "es=s:0.5")
+
+		tc, err := tracecontext.FromHeadersWithOptions(headers, tracecontext.Options{Fallback: ElasticFallback})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceParent.String()).To(Equal("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"))
+
+		rate, ok := ElasticSampleRate(tc.TraceState)
+		Expect(ok).To(BeTrue())
+		Expect(rate).To(Equal(0.5))
+	})
+
+	It("reads the sample rate only from valid list members", func() {
+		for _, s := range []string{"", "other=s:0.5", "es=x:1", "es=s:high", "es=s:1.5", "es=s:-1"} {
+			ts, err := tracestate.ParseString(s)
+			Expect(err).NotTo(HaveOccurred(), s)
+			_, ok := ElasticSampleRate(ts)
+			Expect(ok).To(BeFalse(), s)
+		}
+	})
+
+	It("sets the sample rate, keeping other fields", func() {
+		ts, err := tracestate.ParseString("dd=s:1,es=s:1;x:y")
+		Expect(err).NotTo(HaveOccurred())
+
+		ts, err = SetElasticSampleRate(ts, 0.123456)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ts.String()).To(Equal("es=s:0.1235;x:y,dd=s:1"))
+
+		ts, err = SetElasticSampleRate(nil, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ts.String()).To(Equal("es=s:0"))
+
+		_, err = SetElasticSampleRate(nil, 1.5)
+		Expect(err).To(Equal(ErrInvalidElastic))
+	})
+})
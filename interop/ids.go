@@ -0,0 +1,40 @@
+package interop
+
+import (
+	"encoding/hex"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+)
+
+// parseTraceID decodes a trace ID of up to 32 hex characters, padding shorter IDs with leading zeroes
+// as systems with 64-bit trace IDs, or that omit leading zeroes, require.
+func parseTraceID(s string) (id traceparent.TraceID, ok bool) {
+	ok = parseHex(id[:], s)
+	return id, ok && id.IsValid()
+}
+
+// parseSpanID decodes a span ID of up to 16 hex characters, padding shorter IDs with leading zeroes.
+func parseSpanID(s string) (id traceparent.SpanID, ok bool) {
+	ok = parseHex(id[:], s)
+	return id, ok && id.IsValid()
+}
+
+func parseHex(dst []byte, s string) bool {
+	if len(s) == 0 || len(s) > 2*len(dst) {
+		return false
+	}
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	_, err := hex.Decode(dst[len(dst)-len(s)/2:], []byte(s))
+	return err == nil
+}
+
+func newTraceParent(traceID traceparent.TraceID, spanID traceparent.SpanID, recorded bool) traceparent.TraceParent {
+	return traceparent.TraceParent{
+		Version: traceparent.Version,
+		TraceID: traceID,
+		SpanID:  spanID,
+		Flags:   traceparent.Flags{Recorded: recorded},
+	}
+}
@@ -0,0 +1,20 @@
+package interop_test
+
+import (
+	"testing"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestInterop(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Interop Suite")
+}
+
+func mustTraceParent(s string) traceparent.TraceParent {
+	tp, err := traceparent.ParseString(s)
+	Expect(err).NotTo(HaveOccurred())
+	return tp
+}
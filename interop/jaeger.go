@@ -0,0 +1,71 @@
+package interop
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+)
+
+const (
+	// JaegerHeader is the name of the header that carries the Jaeger trace context.
+	JaegerHeader = "uber-trace-id"
+
+	jaegerFlagSampled = 1
+	jaegerFlagDebug   = 2
+)
+
+var (
+	// ErrInvalidJaeger occurs when an `uber-trace-id` header is incorrectly formatted.
+	ErrInvalidJaeger = errors.New("tracecontext: Invalid uber-trace-id header")
+)
+
+// ParseJaeger attempts to convert a Jaeger value, `{trace-id}:{span-id}:{parent-span-id}:{flags}`, to a `TraceParent`.
+// The IDs may omit leading zeroes, and the value may be URL-encoded, as some Jaeger clients produce.
+// The sampled and debug flags are treated as recorded. The deprecated parent span ID is discarded.
+func ParseJaeger(s string) (traceparent.TraceParent, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "%") {
+		unescaped, err := url.QueryUnescape(s)
+		if err != nil {
+			return traceparent.TraceParent{}, ErrInvalidJaeger
+		}
+		s = unescaped
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) != 4 {
+		return traceparent.TraceParent{}, ErrInvalidJaeger
+	}
+
+	traceID, ok := parseTraceID(parts[0])
+	if !ok {
+		return traceparent.TraceParent{}, ErrInvalidJaeger
+	}
+	spanID, ok := parseSpanID(parts[1])
+	if !ok {
+		return traceparent.TraceParent{}, ErrInvalidJaeger
+	}
+	var parentSpanID traceparent.SpanID
+	if !parseHex(parentSpanID[:], parts[2]) {
+		return traceparent.TraceParent{}, ErrInvalidJaeger
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return traceparent.TraceParent{}, ErrInvalidJaeger
+	}
+
+	return newTraceParent(traceID, spanID, flags&(jaegerFlagSampled|jaegerFlagDebug) != 0), nil
+}
+
+// FormatJaeger encodes a `TraceParent` as a Jaeger value, with a 0 parent span ID and the sampled flag set if recorded.
+func FormatJaeger(tp traceparent.TraceParent) string {
+	var flags int
+	if tp.Flags.Recorded {
+		flags = jaegerFlagSampled
+	}
+	return fmt.Sprintf("%s:%s:0:%d", tp.TraceID, tp.SpanID, flags)
+}
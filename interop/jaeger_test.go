@@ -0,0 +1,52 @@
+package interop_test
+
+import (
+	. "github.com/lightstep/tracecontext.go/interop"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Jaeger", func() {
+	It("parses valid values", func() {
+		for value, expected := range map[string]string{
+			"80f198ee56343ba864fe8b2a57d3eff7:e457b5a2e4d86bd1:0:1":       "00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-01",
+			"80f198ee56343ba864fe8b2a57d3eff7:e457b5a2e4d86bd1:0:3":       "00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-01",
+			"80f198ee56343ba864fe8b2a57d3eff7:e457b5a2e4d86bd1:0:0":       "00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-00",
+			"abc:def:5e3ac9a4f6e3b90:2":                                   "00-00000000000000000000000000000abc-0000000000000def-01",
+			"80f198ee56343ba864fe8b2a57d3eff7%3Ae457b5a2e4d86bd1%3A0%3A1": "00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-01",
+		} {
+			tp, err := ParseJaeger(value)
+			Expect(err).NotTo(HaveOccurred(), value)
+			Expect(tp.String()).To(Equal(expected), value)
+		}
+	})
+
+	It("errors on invalid values", func() {
+		for _, value := range []string{
+			"",
+			"80f198ee56343ba864fe8b2a57d3eff7:e457b5a2e4d86bd1:0",
+			"80f198ee56343ba864fe8b2a57d3eff7:e457b5a2e4d86bd1:0:1:1",
+			"0:e457b5a2e4d86bd1:0:1",
+			"80f198ee56343ba864fe8b2a57d3eff7:0:0:1",
+			"180f198ee56343ba864fe8b2a57d3eff7:e457b5a2e4d86bd1:0:1",
+			"80f198ee56343ba864fe8b2a57d3eff7:e457b5a2e4d86bd1::1",
+			"80f198ee56343ba864fe8b2a57d3eff7:e457b5a2e4d86bd1:0:x",
+			"80f198ee56343ba864fe8b2a57d3eff7%3Ae457b5a2e4d86bd1%3A0%3",
+		} {
+			_, err := ParseJaeger(value)
+			Expect(err).To(Equal(ErrInvalidJaeger), value)
+		}
+	})
+
+	It("round-trips through FormatJaeger", func() {
+		for _, s := range []string{
+			"00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-01",
+			"00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-00",
+		} {
+			tp, err := ParseJaeger(FormatJaeger(mustTraceParent(s)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tp.String()).To(Equal(s))
+		}
+		Expect(FormatJaeger(mustTraceParent("00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-01"))).To(Equal("80f198ee56343ba864fe8b2a57d3eff7:e457b5a2e4d86bd1:0:1"))
+	})
+})
@@ -0,0 +1,313 @@
+package interop
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	tracecontext "github.com/lightstep/tracecontext.go"
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+)
+
+const (
+	// NewRelicHeader is the name of the header that carries the New Relic distributed tracing payload, as base64-encoded JSON.
+	NewRelicHeader = "newrelic"
+	// NewRelicSystemID is the system ID of the multi-tenant key, `<trust key>@nr`, of the New Relic `tracestate` list member.
+	NewRelicSystemID = "nr"
+
+	newRelicVersion    = 0
+	newRelicMinorVer   = 1
+	newRelicFieldCount = 9
+)
+
+var (
+	// ErrInvalidNewRelic occurs when a `newrelic` header or a New Relic list member is missing or incorrectly formatted.
+	ErrInvalidNewRelic = errors.New("tracecontext: Invalid New Relic trace context")
+
+	newRelicTypes = []string{"App", "Browser", "Mobile"}
+)
+
+// NewRelic is the trace context propagated by New Relic agents, in the `newrelic` header or in a `<trust key>@nr` list member.
+type NewRelic struct {
+	// Type is the kind of agent that created the payload, i.e., `App`, `Browser` or `Mobile`.
+	Type string
+	// AccountID identifies the New Relic account of the agent.
+	AccountID string
+	// AppID identifies the application within the account.
+	AppID string
+	// TrustKey identifies the account trusted to continue the trace. If it is empty, the `AccountID` is used.
+	TrustKey string
+	// TraceID is only carried by the `newrelic` header. It is zero for a list member, whose trace ID is that of the `traceparent`.
+	TraceID traceparent.TraceID
+	// SpanID identifies the parent span. It may be zero if the agent did not record span events.
+	SpanID traceparent.SpanID
+	// TransactionID identifies the parent transaction. It may be empty.
+	TransactionID string
+	// Sampled is the sampling decision, or nil if the decision has not been made.
+	Sampled *bool
+	// Priority is the sampling priority, between 0 and 2 where sampled transactions have a priority of at least 1,
+	// or nil if the decision has not been made.
+	Priority *float64
+	// Timestamp is when the payload was created, with millisecond precision.
+	Timestamp time.Time
+}
+
+type newRelicPayload struct {
+	Version []int `json:"v"`
+	Data    struct {
+		Type          string   `json:"ty"`
+		AccountID     string   `json:"ac"`
+		AppID         string   `json:"ap"`
+		SpanID        string   `json:"id,omitempty"`
+		TraceID       string   `json:"tr"`
+		Priority      *float64 `json:"pr,omitempty"`
+		Sampled       *bool    `json:"sa,omitempty"`
+		Timestamp     int64    `json:"ti"`
+		TrustKey      string   `json:"tk,omitempty"`
+		TransactionID string   `json:"tx,omitempty"`
+	} `json:"d"`
+}
+
+// ParseNewRelic attempts to decode a `newrelic` header value, i.e., base64-encoded JSON.
+// It returns an error if the major version is not 0, or if a required field is missing or invalid.
+// At least one of the span and transaction IDs must be present.
+func ParseNewRelic(s string) (NewRelic, error) {
+	var n NewRelic
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return n, ErrInvalidNewRelic
+	}
+	var p newRelicPayload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return n, ErrInvalidNewRelic
+	}
+	if len(p.Version) == 0 || p.Version[0] != newRelicVersion {
+		return n, ErrInvalidNewRelic
+	}
+
+	d := p.Data
+	traceID, ok := parseTraceID(d.TraceID)
+	if !ok || !isNewRelicType(d.Type) || d.AccountID == "" || d.AppID == "" || d.Timestamp <= 0 {
+		return n, ErrInvalidNewRelic
+	}
+	if d.SpanID == "" && d.TransactionID == "" {
+		return n, ErrInvalidNewRelic
+	}
+
+	n = NewRelic{
+		Type:          d.Type,
+		AccountID:     d.AccountID,
+		AppID:         d.AppID,
+		TrustKey:      d.TrustKey,
+		TraceID:       traceID,
+		TransactionID: d.TransactionID,
+		Sampled:       d.Sampled,
+		Priority:      d.Priority,
+		Timestamp:     fromUnixMilli(d.Timestamp),
+	}
+	if d.SpanID != "" {
+		if n.SpanID, ok = parseSpanID(d.SpanID); !ok {
+			return NewRelic{}, ErrInvalidNewRelic
+		}
+	}
+	return n, nil
+}
+
+// FormatNewRelic encodes a `NewRelic` as a `newrelic` header value.
+// A trace ID whose high 64 bits are zero is encoded in 16 hex characters, and otherwise in 32, and the trust key is omitted if it is the account ID.
+func FormatNewRelic(n NewRelic) string {
+	var p newRelicPayload
+	p.Version = []int{newRelicVersion, newRelicMinorVer}
+	p.Data.Type = n.Type
+	p.Data.AccountID = n.AccountID
+	p.Data.AppID = n.AppID
+	p.Data.TraceID = n.TraceID.String()
+	if high, _ := n.TraceID.Uint64s(); high == 0 {
+		p.Data.TraceID = p.Data.TraceID[16:]
+	}
+	if n.SpanID.IsValid() {
+		p.Data.SpanID = n.SpanID.String()
+	}
+	p.Data.Priority = n.Priority
+	p.Data.Sampled = n.Sampled
+	p.Data.Timestamp = toUnixMilli(n.Timestamp)
+	if n.TrustKey != n.AccountID {
+		p.Data.TrustKey = n.TrustKey
+	}
+	p.Data.TransactionID = n.TransactionID
+
+	// Marshalling cannot fail, as the payload contains only strings and numbers.
+	data, _ := json.Marshal(p)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// ParseNewRelicMember attempts to decode a `<trust key>@nr` list member, whose value is
+// `version-parentType-accountId-appId-spanId-transactionId-sampled-priority-timestamp`.
+// The span ID, transaction ID, sampled flag and priority may be empty. Fields appended by later versions are ignored.
+func ParseNewRelicMember(m tracestate.Member) (NewRelic, error) {
+	var n NewRelic
+	if m.SystemID != NewRelicSystemID || m.TenantID == "" {
+		return n, ErrInvalidNewRelic
+	}
+
+	fields := strings.Split(m.Value, "-")
+	if len(fields) < newRelicFieldCount || fields[0] != strconv.Itoa(newRelicVersion) {
+		return n, ErrInvalidNewRelic
+	}
+
+	parentType, err := strconv.Atoi(fields[1])
+	if err != nil || parentType < 0 || parentType >= len(newRelicTypes) {
+		return n, ErrInvalidNewRelic
+	}
+	n.Type = newRelicTypes[parentType]
+
+	n.AccountID, n.AppID, n.TrustKey = fields[2], fields[3], m.TenantID
+	if n.AccountID == "" || n.AppID == "" {
+		return NewRelic{}, ErrInvalidNewRelic
+	}
+
+	var ok bool
+	if fields[4] != "" {
+		if n.SpanID, ok = parseSpanID(fields[4]); !ok {
+			return NewRelic{}, ErrInvalidNewRelic
+		}
+	}
+	n.TransactionID = fields[5]
+
+	switch fields[6] {
+	case "1", "0":
+		sampled := fields[6] == "1"
+		n.Sampled = &sampled
+	case "":
+	default:
+		return NewRelic{}, ErrInvalidNewRelic
+	}
+
+	if fields[7] != "" {
+		priority, err := strconv.ParseFloat(fields[7], 64)
+		if err != nil {
+			return NewRelic{}, ErrInvalidNewRelic
+		}
+		n.Priority = &priority
+	}
+
+	timestamp, err := strconv.ParseInt(fields[8], 10, 64)
+	if err != nil || timestamp <= 0 {
+		return NewRelic{}, ErrInvalidNewRelic
+	}
+	n.Timestamp = fromUnixMilli(timestamp)
+
+	return n, nil
+}
+
+// NewRelicMember encodes a `NewRelic` as a `<trust key>@nr` list member, with the priority rounded to 6 decimal places.
+// The trace ID is not encoded, as it is that of the `traceparent`.
+// It returns an error if the type is unknown, or if the result is not a valid list member.
+func NewRelicMember(n NewRelic) (tracestate.Member, error) {
+	parentType := -1
+	for i, t := range newRelicTypes {
+		if t == n.Type {
+			parentType = i
+		}
+	}
+	if parentType < 0 || n.AccountID == "" || n.AppID == "" {
+		return tracestate.Member{}, ErrInvalidNewRelic
+	}
+
+	var spanID, sampled, priority string
+	if n.SpanID.IsValid() {
+		spanID = n.SpanID.String()
+	}
+	if n.Sampled != nil {
+		sampled = "0"
+		if *n.Sampled {
+			sampled = "1"
+		}
+	}
+	if n.Priority != nil {
+		priority = strconv.FormatFloat(math.Round(*n.Priority*1e6)/1e6, 'f', -1, 64)
+	}
+
+	trustKey := n.TrustKey
+	if trustKey == "" {
+		trustKey = n.AccountID
+	}
+
+	return tracestate.NewMultiTenantMember(trustKey, NewRelicSystemID, strings.Join([]string{
+		strconv.Itoa(newRelicVersion),
+		strconv.Itoa(parentType),
+		n.AccountID,
+		n.AppID,
+		spanID,
+		n.TransactionID,
+		sampled,
+		priority,
+		strconv.FormatInt(toUnixMilli(n.Timestamp), 10),
+	}, "-"))
+}
+
+// NewRelicFromTraceState returns the first `<trust key>@nr` list member that can be decoded, and whether there is one.
+func NewRelicFromTraceState(ts tracestate.TraceState) (NewRelic, bool) {
+	for _, m := range ts {
+		if n, err := ParseNewRelicMember(m); err == nil {
+			return n, true
+		}
+	}
+	return NewRelic{}, false
+}
+
+// TraceParent returns the `TraceParent` of the parent span, recorded if it was sampled.
+// The transaction ID is used as the span ID if the latter is zero, as New Relic agents do.
+func (n NewRelic) TraceParent() traceparent.TraceParent {
+	spanID := n.SpanID
+	if !spanID.IsValid() {
+		spanID, _ = parseSpanID(n.TransactionID)
+	}
+	return newTraceParent(n.TraceID, spanID, n.Sampled != nil && *n.Sampled)
+}
+
+// FromNewRelicHeaders attempts to convert the `newrelic` header to a `TraceContext`, returning the decoded payload separately.
+// The `TraceState` contains the equivalent `<trust key>@nr` list member, so the trace can continue through New Relic agents
+// that support W3C trace context, unless the payload cannot be encoded as one.
+func FromNewRelicHeaders(headers http.Header) (tracecontext.TraceContext, NewRelic, error) {
+	var tc tracecontext.TraceContext
+
+	n, err := ParseNewRelic(headers.Get(NewRelicHeader))
+	if err != nil {
+		return tc, n, err
+	}
+	tp := n.TraceParent()
+	if !tp.SpanID.IsValid() {
+		return tc, NewRelic{}, ErrInvalidNewRelic
+	}
+
+	tc.TraceParent = tp
+	if m, err := NewRelicMember(n); err == nil {
+		tc.TraceState = tracestate.TraceState{m}
+	}
+	return tc, n, nil
+}
+
+func isNewRelicType(t string) bool {
+	for _, known := range newRelicTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+func fromUnixMilli(ms int64) time.Time {
+	return time.Unix(ms/1000, ms%1000*int64(time.Millisecond))
+}
+
+func toUnixMilli(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,130 @@
+package interop_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	. "github.com/lightstep/tracecontext.go/interop"
+	"github.com/lightstep/tracecontext.go/tracestate"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New Relic", func() {
+	encode := func(payload string) string {
+		return base64.StdEncoding.EncodeToString([]byte(payload))
+	}
+	payload := encode(`{"v":[0,1],"d":{"ty":"App","ac":"33","ap":"2827902","id":"7d3efb1b173fecfa","tr":"e8b91a159289ff74",` +
+		`"pr":1.234567,"sa":true,"ti":1518469636035,"tk":"1","tx":"e8b91a159289ff74"}}`)
+
+	It("parses the newrelic header", func() {
+		n, err := ParseNewRelic(payload)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n.Type).To(Equal("App"))
+		Expect(n.AccountID).To(Equal("33"))
+		Expect(n.AppID).To(Equal("2827902"))
+		Expect(n.TrustKey).To(Equal("1"))
+		Expect(n.TraceID.String()).To(Equal("0000000000000000e8b91a159289ff74"))
+		Expect(n.SpanID.String()).To(Equal("7d3efb1b173fecfa"))
+		Expect(n.TransactionID).To(Equal("e8b91a159289ff74"))
+		Expect(*n.Sampled).To(BeTrue())
+		Expect(*n.Priority).To(Equal(1.234567))
+		Expect(n.Timestamp.Equal(time.Unix(1518469636, 35*int64(time.Millisecond)))).To(BeTrue())
+		Expect(n.TraceParent().String()).To(Equal("00-0000000000000000e8b91a159289ff74-7d3efb1b173fecfa-01"))
+
+		roundTripped, err := ParseNewRelic(FormatNewRelic(n))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(roundTripped.Timestamp.Equal(n.Timestamp)).To(BeTrue())
+		roundTripped.Timestamp = n.Timestamp
+		Expect(roundTripped).To(Equal(n))
+	})
+
+	It("falls back to the transaction ID, and defers an undecided sampling decision", func() {
+		n, err := ParseNewRelic(encode(`{"v":[0,2],"d":{"ty":"Browser","ac":"33","ap":"1","tr":"4bf92f3577b34da6a3ce929d0e0e4736","ti":1,"tx":"00f067aa0ba902b7"}}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n.SpanID.IsValid()).To(BeFalse())
+		Expect(n.Sampled).To(BeNil())
+		Expect(n.Priority).To(BeNil())
+		Expect(n.TraceParent().String()).To(Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"))
+	})
+
+	It("errors on invalid headers", func() {
+		for _, value := range []string{
+			"",
+			"not base64!",
+			encode(`not json`),
+			encode(`{"v":[1,0],"d":{"ty":"App","ac":"33","ap":"1","id":"7d3efb1b173fecfa","tr":"e8b91a159289ff74","ti":1}}`),
+			encode(`{"v":[0,1],"d":{"ty":"Server","ac":"33","ap":"1","id":"7d3efb1b173fecfa","tr":"e8b91a159289ff74","ti":1}}`),
+			encode(`{"v":[0,1],"d":{"ty":"App","ap":"1","id":"7d3efb1b173fecfa","tr":"e8b91a159289ff74","ti":1}}`),
+			encode(`{"v":[0,1],"d":{"ty":"App","ac":"33","ap":"1","tr":"e8b91a159289ff74","ti":1}}`),
+			encode(`{"v":[0,1],"d":{"ty":"App","ac":"33","ap":"1","id":"xyz","tr":"e8b91a159289ff74","ti":1}}`),
+			encode(`{"v":[0,1],"d":{"ty":"App","ac":"33","ap":"1","id":"7d3efb1b173fecfa","tr":"0","ti":1}}`),
+			encode(`{"v":[0,1],"d":{"ty":"App","ac":"33","ap":"1","id":"7d3efb1b173fecfa","tr":"e8b91a159289ff74"}}`),
+		} {
+			_, err := ParseNewRelic(value)
+			Expect(err).To(Equal(ErrInvalidNewRelic), value)
+		}
+	})
+
+	It("parses and encodes tracestate list members", func() {
+		ts, err := tracestate.ParseString("33@nr=0-0-33-2827902-7d3efb1b173fecfa-e8b91a159289ff74-1-1.23456-1518469636035,es=s:0.5")
+		Expect(err).NotTo(HaveOccurred())
+
+		n, ok := NewRelicFromTraceState(ts)
+		Expect(ok).To(BeTrue())
+		Expect(n.TrustKey).To(Equal("33"))
+		Expect(n.Type).To(Equal("App"))
+		Expect(n.SpanID.String()).To(Equal("7d3efb1b173fecfa"))
+		Expect(*n.Sampled).To(BeTrue())
+		Expect(*n.Priority).To(Equal(1.23456))
+		Expect(n.Timestamp.Equal(time.Unix(1518469636, 35*int64(time.Millisecond)))).To(BeTrue())
+
+		m, err := NewRelicMember(n)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m).To(Equal(ts[0]))
+
+		m, err = tracestate.NewMultiTenantMember("1", "nr", "0-2-33-5043-----1518469636035-future")
+		Expect(err).NotTo(HaveOccurred())
+		n, err = ParseNewRelicMember(m)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n.Type).To(Equal("Mobile"))
+		Expect(n.Sampled).To(BeNil())
+		Expect(n.Priority).To(BeNil())
+
+		_, ok = NewRelicFromTraceState(tracestate.TraceState{ts[1]})
+		Expect(ok).To(BeFalse())
+	})
+
+	It("errors on invalid tracestate list members", func() {
+		for _, s := range []string{
+			"nr=0-0-33-2827902-7d3efb1b173fecfa-e8b91a159289ff74-1-1.23456-1518469636035",
+			"33@dd=0-0-33-2827902-7d3efb1b173fecfa-e8b91a159289ff74-1-1.23456-1518469636035",
+			"33@nr=1-0-33-2827902-7d3efb1b173fecfa-e8b91a159289ff74-1-1.23456-1518469636035",
+			"33@nr=0-3-33-2827902-7d3efb1b173fecfa-e8b91a159289ff74-1-1.23456-1518469636035",
+			"33@nr=0-0-33-2827902-7d3efb1b173fecfa-e8b91a159289ff74-true-1.23456-1518469636035",
+			"33@nr=0-0-33-2827902-7d3efb1b173fecfa-e8b91a159289ff74-1-high-1518469636035",
+			"33@nr=0-0-33-2827902-7d3efb1b173fecfa-e8b91a159289ff74-1-1.23456",
+			"33@nr=0-0--2827902-7d3efb1b173fecfa-e8b91a159289ff74-1-1.23456-1518469636035",
+		} {
+			ts, err := tracestate.ParseString(s)
+			Expect(err).NotTo(HaveOccurred(), s)
+			_, err = ParseNewRelicMember(ts[0])
+			Expect(err).To(Equal(ErrInvalidNewRelic), s)
+		}
+	})
+
+	It("converts the newrelic header to a trace context", func() {
+		h := http.Header{}
+		h.Set("newrelic", payload)
+
+		tc, n, err := FromNewRelicHeaders(h)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n.AccountID).To(Equal("33"))
+		Expect(tc.TraceParent.String()).To(Equal("00-0000000000000000e8b91a159289ff74-7d3efb1b173fecfa-01"))
+		Expect(tc.TraceState.String()).To(Equal("1@nr=0-0-33-2827902-7d3efb1b173fecfa-e8b91a159289ff74-1-1.234567-1518469636035"))
+
+		_, _, err = FromNewRelicHeaders(http.Header{})
+		Expect(err).To(Equal(ErrInvalidNewRelic))
+	})
+})
@@ -0,0 +1,86 @@
+package interop
+
+import (
+	"errors"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	tracecontext "github.com/lightstep/tracecontext.go"
+)
+
+const (
+	// OTTracerTraceIDHeader is the name of the header that carries the trace ID in the Lightstep and OpenTracing basic-tracer format.
+	OTTracerTraceIDHeader = "ot-tracer-traceid"
+	// OTTracerSpanIDHeader is the name of the header that carries the span ID in the Lightstep and OpenTracing basic-tracer format.
+	OTTracerSpanIDHeader = "ot-tracer-spanid"
+	// OTTracerSampledHeader is the name of the header that carries the sampling decision in the Lightstep and OpenTracing basic-tracer format.
+	OTTracerSampledHeader = "ot-tracer-sampled"
+	// OTBaggagePrefix is the prefix of the names of the headers that carry baggage items in the Lightstep and OpenTracing basic-tracer format.
+	OTBaggagePrefix = "ot-baggage-"
+)
+
+var (
+	// ErrInvalidOTTracer occurs when the `ot-tracer-*` headers are missing or incorrectly formatted.
+	ErrInvalidOTTracer = errors.New("tracecontext: Invalid ot-tracer headers")
+
+	otBaggagePrefix = textproto.CanonicalMIMEHeaderKey(OTBaggagePrefix)
+)
+
+// FromOTTracerHeaders attempts to convert the `ot-tracer-*` headers to a `TraceContext`, returning the `ot-baggage-*` items separately
+// with their keys in lowercase. The `TraceState` is empty, as the format has no equivalent.
+//
+// A 64-bit trace ID is padded with leading zeroes to 128 bits, and is restored to 64 bits by `SetOTTracerHeaders`,
+// so IDs that originated as 64-bit round-trip unchanged. As in the Lightstep tracers, a missing `ot-tracer-sampled` header means sampled.
+func FromOTTracerHeaders(headers http.Header) (tracecontext.TraceContext, map[string]string, error) {
+	var tc tracecontext.TraceContext
+
+	traceID, ok := parseTraceID(headers.Get(OTTracerTraceIDHeader))
+	if !ok {
+		return tc, nil, ErrInvalidOTTracer
+	}
+	spanID, ok := parseSpanID(headers.Get(OTTracerSpanIDHeader))
+	if !ok {
+		return tc, nil, ErrInvalidOTTracer
+	}
+
+	sampled := true
+	if s := headers.Get(OTTracerSampledHeader); s != "" {
+		var err error
+		if sampled, err = strconv.ParseBool(s); err != nil {
+			return tc, nil, ErrInvalidOTTracer
+		}
+	}
+
+	var baggage map[string]string
+	for name, values := range headers {
+		if !strings.HasPrefix(name, otBaggagePrefix) || len(values) == 0 || len(name) == len(otBaggagePrefix) {
+			continue
+		}
+		if baggage == nil {
+			baggage = make(map[string]string)
+		}
+		baggage[strings.ToLower(name[len(otBaggagePrefix):])] = values[0]
+	}
+
+	tc.TraceParent = newTraceParent(traceID, spanID, sampled)
+	return tc, baggage, nil
+}
+
+// SetOTTracerHeaders sets the `ot-tracer-*` headers from the `TraceContext`'s `TraceParent`, and an `ot-baggage-*` header for each baggage item.
+// A trace ID whose high 64 bits are zero is encoded in 16 hex characters, and otherwise in 32.
+func SetOTTracerHeaders(headers http.Header, tc tracecontext.TraceContext, baggage map[string]string) {
+	tp := tc.TraceParent
+	traceID := tp.TraceID.String()
+	if high, _ := tp.TraceID.Uint64s(); high == 0 {
+		traceID = traceID[16:]
+	}
+
+	headers.Set(OTTracerTraceIDHeader, traceID)
+	headers.Set(OTTracerSpanIDHeader, tp.SpanID.String())
+	headers.Set(OTTracerSampledHeader, strconv.FormatBool(tp.Flags.Recorded))
+	for key, value := range baggage {
+		headers.Set(OTBaggagePrefix+key, value)
+	}
+}
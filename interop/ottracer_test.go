@@ -0,0 +1,73 @@
+package interop_test
+
+import (
+	"net/http"
+
+	tracecontext "github.com/lightstep/tracecontext.go"
+	. "github.com/lightstep/tracecontext.go/interop"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ot-tracer", func() {
+	headers := func(traceID, spanID, sampled string) http.Header {
+		h := http.Header{}
+		h.Set("ot-tracer-traceid", traceID)
+		h.Set("ot-tracer-spanid", spanID)
+		if sampled != "" {
+			h.Set("ot-tracer-sampled", sampled)
+		}
+		return h
+	}
+
+	It("converts headers to a trace context and baggage", func() {
+		h := headers("64fe8b2a57d3eff7", "e457b5a2e4d86bd1", "false")
+		h.Set("ot-baggage-UserID", "42")
+		h.Set("ot-baggage-region", "eu")
+
+		tc, baggage, err := FromOTTracerHeaders(h)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceParent.String()).To(Equal("00-000000000000000064fe8b2a57d3eff7-e457b5a2e4d86bd1-00"))
+		Expect(tc.TraceState).To(BeEmpty())
+		Expect(baggage).To(Equal(map[string]string{"userid": "42", "region": "eu"}))
+
+		tc, baggage, err = FromOTTracerHeaders(headers("80f198ee56343ba864fe8b2a57d3eff7", "e457b5a2e4d86bd1", ""))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceParent.String()).To(Equal("00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-01"))
+		Expect(baggage).To(BeNil())
+	})
+
+	It("errors on missing or invalid headers", func() {
+		for _, h := range []http.Header{
+			{},
+			headers("64fe8b2a57d3eff7", "", ""),
+			headers("", "e457b5a2e4d86bd1", ""),
+			headers("0", "e457b5a2e4d86bd1", ""),
+			headers("64fe8b2a57d3eff7", "e457b5a2e4d86bd1", "maybe"),
+			headers("180f198ee56343ba864fe8b2a57d3eff7", "e457b5a2e4d86bd1", ""),
+			headers("64fe8b2a57d3eff7", "xyz", ""),
+		} {
+			_, _, err := FromOTTracerHeaders(h)
+			Expect(err).To(Equal(ErrInvalidOTTracer))
+		}
+	})
+
+	It("round-trips 64-bit and 128-bit trace IDs", func() {
+		for _, traceID := range []string{"64fe8b2a57d3eff7", "80f198ee56343ba864fe8b2a57d3eff7"} {
+			in := headers(traceID, "e457b5a2e4d86bd1", "true")
+			in.Set("ot-baggage-key", "value")
+			tc, baggage, err := FromOTTracerHeaders(in)
+			Expect(err).NotTo(HaveOccurred())
+
+			out := http.Header{}
+			SetOTTracerHeaders(out, tc, baggage)
+			Expect(out).To(Equal(in))
+		}
+	})
+
+	It("converts a trace context to headers", func() {
+		out := http.Header{}
+		SetOTTracerHeaders(out, tracecontext.TraceContext{TraceParent: mustTraceParent("00-80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-00")}, nil)
+		Expect(out).To(Equal(headers("80f198ee56343ba864fe8b2a57d3eff7", "e457b5a2e4d86bd1", "false")))
+	})
+})
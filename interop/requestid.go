@@ -0,0 +1,139 @@
+package interop
+
+import (
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	tracecontext "github.com/lightstep/tracecontext.go"
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+)
+
+const (
+	// RequestIDHeader is the name of the header used by the legacy hierarchical format of .NET `System.Diagnostics.Activity`,
+	// e.g., `|root.1.2.`.
+	RequestIDHeader = "Request-Id"
+	// CorrelationContextHeader is the name of the header that carries correlation items alongside `Request-Id`.
+	CorrelationContextHeader = "Correlation-Context"
+	// RequestIDTraceStateKey is the key of the `tracestate` list member that preserves the root of a `Request-Id`.
+	RequestIDTraceStateKey = "requestid"
+
+	maxRequestIDLength = 1024
+)
+
+// ErrInvalidRequestID occurs when a `Request-Id` header is missing or incorrectly formatted.
+var ErrInvalidRequestID = errors.New("tracecontext: Invalid Request-Id header")
+
+// FromRequestIDHeaders attempts to convert the `Request-Id` header to a `TraceContext`, returning the `Correlation-Context` items separately.
+//
+// The root of the `Request-Id`, i.e., everything between the leading `|` and the first `.`, becomes the trace ID if it is 32 hex characters,
+// and is otherwise hashed to derive one, so every service in the same legacy operation agrees on the trace ID.
+// The span ID is derived by hashing the whole `Request-Id`.
+// The root is preserved in a `requestid` list member so `SetRequestIDHeaders` can restore it. It is query-escaped,
+// as roots often end in base64 padding, and is omitted if it is too long for a list member value.
+// The `TraceParent` is not recorded, as the format has no sampling decision.
+// Incorrectly formatted `Correlation-Context` items are skipped.
+func FromRequestIDHeaders(headers http.Header) (tracecontext.TraceContext, map[string]string, error) {
+	var tc tracecontext.TraceContext
+
+	requestID := strings.TrimSpace(headers.Get(RequestIDHeader))
+	root, ok := requestIDRoot(requestID)
+	if !ok {
+		return tc, nil, ErrInvalidRequestID
+	}
+
+	var spanID traceparent.SpanID
+	sum := sha256.Sum256([]byte(requestID))
+	copy(spanID[:], sum[:])
+	if !spanID.IsValid() {
+		return tc, nil, ErrInvalidRequestID
+	}
+
+	tc.TraceParent = newTraceParent(requestIDTraceID(root), spanID, false)
+	if m, err := tracestate.NewSimpleMember(RequestIDTraceStateKey, url.QueryEscape(root)); err == nil {
+		tc.TraceState = tracestate.TraceState{m}
+	}
+	return tc, parseCorrelationContext(headers[CorrelationContextHeader]), nil
+}
+
+// SetRequestIDHeaders sets the `Request-Id` header for a call to a legacy service, in the form `|root.spanid.`,
+// and the `Correlation-Context` header if there are any items.
+// The root is restored from the `requestid` list member if it still matches the trace ID, and is otherwise the trace ID itself.
+func SetRequestIDHeaders(headers http.Header, tc tracecontext.TraceContext, correlation map[string]string) {
+	root := tc.TraceParent.TraceID.String()
+	if m, ok := tc.TraceState.GetKey(RequestIDTraceStateKey); ok {
+		if original, err := url.QueryUnescape(m.Value); err == nil && original != "" && requestIDTraceID(original) == tc.TraceParent.TraceID {
+			root = original
+		}
+	}
+	headers.Set(RequestIDHeader, "|"+root+"."+tc.TraceParent.SpanID.String()+".")
+
+	if len(correlation) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(correlation))
+	for key := range correlation {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := make([]string, len(keys))
+	for i, key := range keys {
+		items[i] = key + "=" + correlation[key]
+	}
+	headers.Set(CorrelationContextHeader, strings.Join(items, ","))
+}
+
+// requestIDRoot returns the root of a hierarchical `Request-Id`, or the whole of a flat one.
+func requestIDRoot(requestID string) (string, bool) {
+	if len(requestID) > maxRequestIDLength {
+		return "", false
+	}
+
+	root := requestID
+	if strings.HasPrefix(root, "|") {
+		root = root[1:]
+		if i := strings.IndexAny(root, "._"); i >= 0 {
+			root = root[:i]
+		}
+	}
+	return root, root != ""
+}
+
+func requestIDTraceID(root string) traceparent.TraceID {
+	if len(root) == 32 {
+		if id, ok := parseTraceID(root); ok {
+			return id
+		}
+	}
+
+	var id traceparent.TraceID
+	sum := sha256.Sum256([]byte(root))
+	copy(id[:], sum[:])
+	return id
+}
+
+func parseCorrelationContext(lines []string) map[string]string {
+	var correlation map[string]string
+	for _, line := range lines {
+		for _, item := range strings.Split(line, ",") {
+			i := strings.Index(item, "=")
+			if i <= 0 {
+				continue
+			}
+			key, value := strings.TrimSpace(item[:i]), strings.TrimSpace(item[i+1:])
+			if key == "" {
+				continue
+			}
+			if correlation == nil {
+				correlation = make(map[string]string)
+			}
+			correlation[key] = value
+		}
+	}
+	return correlation
+}
@@ -0,0 +1,100 @@
+package interop_test
+
+import (
+	"net/http"
+
+	tracecontext "github.com/lightstep/tracecontext.go"
+	. "github.com/lightstep/tracecontext.go/interop"
+	"github.com/lightstep/tracecontext.go/tracestate"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Request-Id", func() {
+	headers := func(requestID string) http.Header {
+		h := http.Header{}
+		h.Set("Request-Id", requestID)
+		return h
+	}
+
+	It("uses a 32 hex character root as the trace ID", func() {
+		tc, correlation, err := FromRequestIDHeaders(headers("|4bf92f3577b34da6a3ce929d0e0e4736.1.2."))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceParent.TraceID.String()).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		Expect(tc.TraceParent.SpanID.IsValid()).To(BeTrue())
+		Expect(tc.TraceParent.Flags.Recorded).To(BeFalse())
+		Expect(tc.TraceState.String()).To(Equal("requestid=4bf92f3577b34da6a3ce929d0e0e4736"))
+		Expect(correlation).To(BeNil())
+	})
+
+	It("hashes other roots deterministically, deriving the span ID from the whole header", func() {
+		a, _, err := FromRequestIDHeaders(headers("|Mw7Yf8hvfk0=.1.2."))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.TraceParent.TraceID.IsValid()).To(BeTrue())
+		Expect(a.TraceState).To(Equal(tracestate.TraceState{{SystemID: "requestid", Value: "Mw7Yf8hvfk0%3D"}}))
+
+		b, _, err := FromRequestIDHeaders(headers("|Mw7Yf8hvfk0=.1.2."))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b).To(Equal(a))
+
+		c, _, err := FromRequestIDHeaders(headers("|Mw7Yf8hvfk0=.1.3."))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.TraceParent.TraceID).To(Equal(a.TraceParent.TraceID))
+		Expect(c.TraceParent.SpanID).NotTo(Equal(a.TraceParent.SpanID))
+
+		d, _, err := FromRequestIDHeaders(headers("|other.1.2."))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d.TraceParent.TraceID).NotTo(Equal(a.TraceParent.TraceID))
+
+		long := string(make([]byte, 300))
+		e, _, err := FromRequestIDHeaders(headers("|" + long + ".1."))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.TraceState).To(BeEmpty())
+	})
+
+	It("accepts flat IDs and parses Correlation-Context", func() {
+		h := headers("abc123")
+		h.Add("Correlation-Context", "userId=42, region = eu")
+		h.Add("Correlation-Context", "invalid,=empty,tier=gold")
+
+		tc, correlation, err := FromRequestIDHeaders(h)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceState.String()).To(Equal("requestid=abc123"))
+		Expect(correlation).To(Equal(map[string]string{"userId": "42", "region": "eu", "tier": "gold"}))
+	})
+
+	It("errors on missing or invalid headers", func() {
+		for _, h := range []http.Header{
+			{},
+			headers("|"),
+			headers("|.1."),
+			headers("|" + string(make([]byte, 1024)) + ".1."),
+		} {
+			_, _, err := FromRequestIDHeaders(h)
+			Expect(err).To(Equal(ErrInvalidRequestID), h.Get("Request-Id"))
+		}
+	})
+
+	It("sets headers for outgoing calls, restoring the root", func() {
+		tc, _, err := FromRequestIDHeaders(headers("|Mw7Yf8hvfk0=.1.2."))
+		Expect(err).NotTo(HaveOccurred())
+
+		h := http.Header{}
+		SetRequestIDHeaders(h, tc, map[string]string{"region": "eu", "userId": "42"})
+		Expect(h.Get("Request-Id")).To(Equal("|Mw7Yf8hvfk0=." + tc.TraceParent.SpanID.String() + "."))
+		Expect(h.Get("Correlation-Context")).To(Equal("region=eu,userId=42"))
+
+		roundTripped, correlation, err := FromRequestIDHeaders(h)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(roundTripped.TraceParent.TraceID).To(Equal(tc.TraceParent.TraceID))
+		Expect(correlation).To(Equal(map[string]string{"region": "eu", "userId": "42"}))
+
+		h = http.Header{}
+		SetRequestIDHeaders(h, tracecontext.TraceContext{
+			TraceParent: mustTraceParent("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"),
+			TraceState:  tc.TraceState,
+		}, nil)
+		Expect(h.Get("Request-Id")).To(Equal("|0af7651916cd43dd8448eb211c80319c.b7ad6b7169203331."))
+		Expect(h).NotTo(HaveKey("Correlation-Context"))
+	})
+})
@@ -0,0 +1,194 @@
+package interop
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	tracecontext "github.com/lightstep/tracecontext.go"
+	"github.com/lightstep/tracecontext.go/traceparent"
+)
+
+const (
+	// SentryTraceHeader is the name of the header that carries the Sentry trace context.
+	SentryTraceHeader = "sentry-trace"
+	// BaggageHeader is the name of the W3C header that carries the Sentry dynamic sampling context, among other baggage.
+	BaggageHeader = "baggage"
+	// SentryBaggagePrefix is the prefix of the keys of the baggage entries that carry the Sentry dynamic sampling context.
+	SentryBaggagePrefix = "sentry-"
+)
+
+var (
+	// ErrInvalidSentryTrace occurs when a `sentry-trace` header is missing or incorrectly formatted.
+	ErrInvalidSentryTrace = errors.New("tracecontext: Invalid sentry-trace header")
+)
+
+// ParseSentryTrace attempts to convert a Sentry value, `{trace_id}-{span_id}-{sampled}`, to a `TraceParent`.
+// The sampled field is optional, and is `1` or `0` if present. A missing sampled field, which defers the decision, is treated as not recorded.
+// Use `FromSentryHeaders` to keep track of a deferred decision.
+func ParseSentryTrace(s string) (traceparent.TraceParent, error) {
+	tp, _, err := parseSentryTrace(s)
+	return tp, err
+}
+
+func parseSentryTrace(s string) (tp traceparent.TraceParent, deferred bool, err error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 2 || len(parts) > 3 || len(parts[0]) != 32 || len(parts[1]) != 16 {
+		return tp, false, ErrInvalidSentryTrace
+	}
+
+	traceID, ok := parseTraceID(parts[0])
+	if !ok {
+		return tp, false, ErrInvalidSentryTrace
+	}
+	spanID, ok := parseSpanID(parts[1])
+	if !ok {
+		return tp, false, ErrInvalidSentryTrace
+	}
+
+	var sampled bool
+	if len(parts) == 3 {
+		switch parts[2] {
+		case "1":
+			sampled = true
+		case "0":
+		default:
+			return tp, false, ErrInvalidSentryTrace
+		}
+	}
+
+	return newTraceParent(traceID, spanID, sampled), len(parts) == 2, nil
+}
+
+// FormatSentryTrace encodes a `TraceParent` as a Sentry value, with an explicit sampled field.
+func FormatSentryTrace(tp traceparent.TraceParent) string {
+	return formatSentryTrace(tp, false)
+}
+
+func formatSentryTrace(tp traceparent.TraceParent, deferred bool) string {
+	s := tp.TraceID.String() + "-" + tp.SpanID.String()
+	if deferred {
+		return s
+	}
+	if tp.Flags.Recorded {
+		return s + "-1"
+	}
+	return s + "-0"
+}
+
+// Sentry is the Sentry trace context that has no equivalent in a `TraceContext`.
+type Sentry struct {
+	// Deferred reports whether the sampling decision was deferred, i.e., neither the `sentry-trace` header
+	// nor the dynamic sampling context contained one, so that downstream Sentry SDKs make their own decision.
+	Deferred bool
+	// DynamicSamplingContext holds the `sentry-` baggage entries with the prefix removed and their values unescaped,
+	// e.g., `sample_rate` and `public_key`.
+	DynamicSamplingContext map[string]string
+}
+
+// FromSentryHeaders attempts to convert the `sentry-trace` header to a `TraceContext`, returning the Sentry trace context separately.
+// If the `sentry-trace` header defers the sampling decision, the `sampled` entry of the dynamic sampling context is used instead, if any.
+// The `TraceState` is empty, as the format has no equivalent.
+func FromSentryHeaders(headers http.Header) (tracecontext.TraceContext, Sentry, error) {
+	var tc tracecontext.TraceContext
+	var sentry Sentry
+
+	tp, deferred, err := parseSentryTrace(headers.Get(SentryTraceHeader))
+	if err != nil {
+		return tc, sentry, err
+	}
+
+	var dsc map[string]string
+	for _, entry := range parseBaggage(headers[http.CanonicalHeaderKey(BaggageHeader)]) {
+		if !strings.HasPrefix(entry.key, SentryBaggagePrefix) || len(entry.key) == len(SentryBaggagePrefix) {
+			continue
+		}
+		value, err := url.PathUnescape(entry.value)
+		if err != nil {
+			continue
+		}
+		if dsc == nil {
+			dsc = make(map[string]string)
+		}
+		dsc[entry.key[len(SentryBaggagePrefix):]] = value
+	}
+
+	if deferred {
+		switch dsc["sampled"] {
+		case "true":
+			tp.Flags.Recorded = true
+			deferred = false
+		case "false":
+			deferred = false
+		}
+	}
+
+	tc.TraceParent = tp
+	sentry.Deferred = deferred
+	sentry.DynamicSamplingContext = dsc
+	return tc, sentry, nil
+}
+
+// SetSentryHeaders sets the `sentry-trace` header from the `TraceContext`'s `TraceParent`, and a `sentry-` baggage entry
+// for each item of the dynamic sampling context, so a trace started by a Sentry SDK continues with the same sampling.
+// The sampled field is left out of the `sentry-trace` header if the decision was deferred and the `TraceParent` is not recorded,
+// so that a deferred decision is passed on as it was received.
+// Existing `sentry-` baggage entries are replaced, and other baggage entries are kept.
+func SetSentryHeaders(headers http.Header, tc tracecontext.TraceContext, sentry Sentry) {
+	headers.Set(SentryTraceHeader, formatSentryTrace(tc.TraceParent, sentry.Deferred && !tc.TraceParent.Flags.Recorded))
+
+	var entries []string
+	for _, entry := range parseBaggage(headers[http.CanonicalHeaderKey(BaggageHeader)]) {
+		if !strings.HasPrefix(entry.key, SentryBaggagePrefix) {
+			entries = append(entries, entry.raw)
+		}
+	}
+
+	dsc := sentry.DynamicSamplingContext
+	keys := make([]string, 0, len(dsc))
+	for key := range dsc {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		entries = append(entries, SentryBaggagePrefix+key+"="+url.PathEscape(dsc[key]))
+	}
+
+	if len(entries) == 0 {
+		headers.Del(BaggageHeader)
+		return
+	}
+	headers.Set(BaggageHeader, strings.Join(entries, ","))
+}
+
+type baggageEntry struct {
+	key, value, raw string
+}
+
+// parseBaggage splits `baggage` header lines into their entries, discarding any properties from the values.
+// Incorrectly formatted entries are skipped.
+func parseBaggage(lines []string) []baggageEntry {
+	var entries []baggageEntry
+	for _, line := range lines {
+		for _, raw := range strings.Split(line, ",") {
+			raw = strings.TrimSpace(raw)
+			i := strings.Index(raw, "=")
+			if i <= 0 {
+				continue
+			}
+
+			value := raw[i+1:]
+			if j := strings.Index(value, ";"); j >= 0 {
+				value = value[:j]
+			}
+			entries = append(entries, baggageEntry{
+				key:   strings.TrimSpace(raw[:i]),
+				value: strings.TrimSpace(value),
+				raw:   raw,
+			})
+		}
+	}
+	return entries
+}
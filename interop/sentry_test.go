@@ -0,0 +1,131 @@
+package interop_test
+
+import (
+	"net/http"
+
+	tracecontext "github.com/lightstep/tracecontext.go"
+	. "github.com/lightstep/tracecontext.go/interop"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("sentry-trace", func() {
+	It("parses valid values", func() {
+		for value, expected := range map[string]string{
+			"771a43a4192642f0b136d5159a501700-b7ad6b7169203331-1": "00-771a43a4192642f0b136d5159a501700-b7ad6b7169203331-01",
+			"771a43a4192642f0b136d5159a501700-b7ad6b7169203331-0": "00-771a43a4192642f0b136d5159a501700-b7ad6b7169203331-00",
+			"771a43a4192642f0b136d5159a501700-b7ad6b7169203331":   "00-771a43a4192642f0b136d5159a501700-b7ad6b7169203331-00",
+		} {
+			tp, err := ParseSentryTrace(value)
+			Expect(err).NotTo(HaveOccurred(), value)
+			Expect(tp.String()).To(Equal(expected), value)
+		}
+	})
+
+	It("errors on invalid values", func() {
+		for _, value := range []string{
+			"",
+			"771a43a4192642f0b136d5159a501700",
+			"771a43a4192642f0b136d5159a501700-b7ad6b7169203331-true",
+			"771a43a4192642f0b136d5159a501700-b7ad6b7169203331-1-1",
+			"71a43a4192642f0b136d5159a501700-b7ad6b7169203331-1",
+			"00000000000000000000000000000000-b7ad6b7169203331-1",
+			"771a43a4192642f0b136d5159a501700-0000000000000000-1",
+		} {
+			_, err := ParseSentryTrace(value)
+			Expect(err).To(Equal(ErrInvalidSentryTrace), value)
+		}
+	})
+
+	It("round-trips through FormatSentryTrace", func() {
+		for _, s := range []string{
+			"00-771a43a4192642f0b136d5159a501700-b7ad6b7169203331-01",
+			"00-771a43a4192642f0b136d5159a501700-b7ad6b7169203331-00",
+		} {
+			tp, err := ParseSentryTrace(FormatSentryTrace(mustTraceParent(s)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tp.String()).To(Equal(s))
+		}
+	})
+
+	It("converts headers to a trace context and dynamic sampling context", func() {
+		h := http.Header{}
+		h.Set("sentry-trace", "771a43a4192642f0b136d5159a501700-b7ad6b7169203331")
+		h.Add("baggage", "other=x;prop=1, sentry-trace_id=771a43a4192642f0b136d5159a501700,sentry-sample_rate=0.25")
+		h.Add("baggage", "sentry-sampled=true,sentry-transaction=GET%20%2Fcheckout,sentry-=empty,invalid")
+
+		tc, sentry, err := FromSentryHeaders(h)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceParent.String()).To(Equal("00-771a43a4192642f0b136d5159a501700-b7ad6b7169203331-01"))
+		Expect(tc.TraceState).To(BeEmpty())
+		Expect(sentry.Deferred).To(BeFalse())
+		Expect(sentry.DynamicSamplingContext).To(Equal(map[string]string{
+			"trace_id":    "771a43a4192642f0b136d5159a501700",
+			"sample_rate": "0.25",
+			"sampled":     "true",
+			"transaction": "GET /checkout",
+		}))
+
+		h.Set("sentry-trace", "771a43a4192642f0b136d5159a501700-b7ad6b7169203331-0")
+		tc, _, err = FromSentryHeaders(h)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceParent.Flags.Recorded).To(BeFalse())
+
+		_, _, err = FromSentryHeaders(http.Header{})
+		Expect(err).To(Equal(ErrInvalidSentryTrace))
+	})
+
+	It("sets headers, replacing only the sentry baggage entries", func() {
+		tc := tracecontext.TraceContext{TraceParent: mustTraceParent("00-771a43a4192642f0b136d5159a501700-00f067aa0ba902b7-01")}
+
+		h := http.Header{}
+		h.Set("baggage", "other=x;prop=1,sentry-sample_rate=1")
+		SetSentryHeaders(h, tc, Sentry{DynamicSamplingContext: map[string]string{"sample_rate": "0.25", "transaction": "GET /checkout"}})
+		Expect(h.Get("sentry-trace")).To(Equal("771a43a4192642f0b136d5159a501700-00f067aa0ba902b7-1"))
+		Expect(h.Get("baggage")).To(Equal("other=x;prop=1,sentry-sample_rate=0.25,sentry-transaction=GET%20%2Fcheckout"))
+
+		_, sentry, err := FromSentryHeaders(h)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sentry.DynamicSamplingContext).To(Equal(map[string]string{"sample_rate": "0.25", "transaction": "GET /checkout"}))
+
+		h = http.Header{}
+		h.Set("baggage", "sentry-sample_rate=1")
+		SetSentryHeaders(h, tc, Sentry{})
+		Expect(h).NotTo(HaveKey("Baggage"))
+	})
+
+	It("passes on a deferred sampling decision", func() {
+		const deferred = "771a43a4192642f0b136d5159a501700-b7ad6b7169203331"
+
+		h := http.Header{}
+		h.Set("sentry-trace", deferred)
+		h.Set("baggage", "sentry-sample_rate=0.25")
+		tc, sentry, err := FromSentryHeaders(h)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceParent.Flags.Recorded).To(BeFalse())
+		Expect(sentry.Deferred).To(BeTrue())
+
+		out := http.Header{}
+		SetSentryHeaders(out, tc, sentry)
+		Expect(out.Get("sentry-trace")).To(Equal(deferred))
+		Expect(out.Get("baggage")).To(Equal("sentry-sample_rate=0.25"))
+
+		tc.TraceParent.Flags.Recorded = true
+		SetSentryHeaders(out, tc, sentry)
+		Expect(out.Get("sentry-trace")).To(Equal(deferred + "-1"))
+
+		for sampled, recorded := range map[string]bool{"true": true, "false": false} {
+			h.Set("baggage", "sentry-sampled="+sampled)
+			tc, sentry, err = FromSentryHeaders(h)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tc.TraceParent.Flags.Recorded).To(Equal(recorded))
+			Expect(sentry.Deferred).To(BeFalse())
+		}
+
+		h.Set("sentry-trace", deferred+"-0")
+		h.Del("baggage")
+		_, sentry, err = FromSentryHeaders(h)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sentry.Deferred).To(BeFalse())
+	})
+})
@@ -0,0 +1,81 @@
+package interop
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+)
+
+const (
+	// XRayHeader is the name of the header that carries the AWS X-Ray trace context.
+	XRayHeader = "X-Amzn-Trace-Id"
+
+	xrayVersion = "1"
+)
+
+var (
+	// ErrInvalidXRay occurs when an `X-Amzn-Trace-Id` header is incorrectly formatted, or is missing the root or parent.
+	ErrInvalidXRay = errors.New("tracecontext: Invalid X-Amzn-Trace-Id header")
+)
+
+// ParseXRay attempts to convert an X-Ray value, e.g., `Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1`,
+// to a `TraceParent`. The trace ID is the concatenation of the root's timestamp and unique ID.
+// A deferred or missing sampling decision is treated as not recorded. Other fields, e.g., `Self` and `Lineage`, are ignored.
+func ParseXRay(s string) (traceparent.TraceParent, error) {
+	var root, parent, sampled string
+	for _, field := range strings.Split(s, ";") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			if kv[0] == "" {
+				continue
+			}
+			return traceparent.TraceParent{}, ErrInvalidXRay
+		}
+		switch kv[0] {
+		case "Root":
+			root = kv[1]
+		case "Parent":
+			parent = kv[1]
+		case "Sampled":
+			sampled = kv[1]
+		}
+	}
+
+	rootParts := strings.Split(root, "-")
+	if len(rootParts) != 3 || rootParts[0] != xrayVersion || len(rootParts[1]) != 8 || len(rootParts[2]) != 24 {
+		return traceparent.TraceParent{}, ErrInvalidXRay
+	}
+	traceID, ok := parseTraceID(rootParts[1] + rootParts[2])
+	if !ok {
+		return traceparent.TraceParent{}, ErrInvalidXRay
+	}
+	if len(parent) != 16 {
+		return traceparent.TraceParent{}, ErrInvalidXRay
+	}
+	spanID, ok := parseSpanID(parent)
+	if !ok {
+		return traceparent.TraceParent{}, ErrInvalidXRay
+	}
+
+	switch sampled {
+	case "", "0", "1", "?":
+	default:
+		return traceparent.TraceParent{}, ErrInvalidXRay
+	}
+
+	return newTraceParent(traceID, spanID, sampled == "1"), nil
+}
+
+// FormatXRay encodes a `TraceParent` as an X-Ray value with the root, parent and sampling decision.
+// The first 4 bytes of the trace ID become the root's timestamp, so only trace IDs that originated in X-Ray
+// carry a meaningful timestamp.
+func FormatXRay(tp traceparent.TraceParent) string {
+	traceID := tp.TraceID.String()
+	sampled := 0
+	if tp.Flags.Recorded {
+		sampled = 1
+	}
+	return fmt.Sprintf("Root=%s-%s-%s;Parent=%s;Sampled=%d", xrayVersion, traceID[:8], traceID[8:], tp.SpanID, sampled)
+}
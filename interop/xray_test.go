@@ -0,0 +1,51 @@
+package interop_test
+
+import (
+	. "github.com/lightstep/tracecontext.go/interop"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("X-Ray", func() {
+	It("parses valid values", func() {
+		for value, expected := range map[string]string{
+			"Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1":                                          "00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-01",
+			"Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=?":                                          "00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-00",
+			"Parent=53995c3f42cd8ad8; Root=1-5759e988-bd862e3fe1be46a994272793":                                                   "00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-00",
+			"Self=1-67891234-12456789abcdef012345678;Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1;": "00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-01",
+		} {
+			tp, err := ParseXRay(value)
+			Expect(err).NotTo(HaveOccurred(), value)
+			Expect(tp.String()).To(Equal(expected), value)
+		}
+	})
+
+	It("errors on invalid values", func() {
+		for _, value := range []string{
+			"",
+			"Root=1-5759e988-bd862e3fe1be46a994272793",
+			"Parent=53995c3f42cd8ad8",
+			"Root=2-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8",
+			"Root=1-5759e988bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8",
+			"Root=1-5759e988-bd862e3fe1be46a99427;Parent=53995c3f42cd8ad8",
+			"Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f",
+			"Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=yes",
+			"Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled",
+		} {
+			_, err := ParseXRay(value)
+			Expect(err).To(Equal(ErrInvalidXRay), value)
+		}
+	})
+
+	It("round-trips through FormatXRay", func() {
+		for _, s := range []string{
+			"00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-01",
+			"00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-00",
+		} {
+			tp, err := ParseXRay(FormatXRay(mustTraceParent(s)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tp.String()).To(Equal(s))
+		}
+		Expect(FormatXRay(mustTraceParent("00-5759e988bd862e3fe1be46a994272793-53995c3f42cd8ad8-01"))).To(Equal("Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"))
+	})
+})
@@ -0,0 +1,60 @@
+package tracecontext
+
+import (
+	"net/http"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+)
+
+// LazyTraceContext is a `TraceContext` whose `tracestate` is parsed only if a list member is accessed or modified,
+// and is otherwise propagated exactly as it was received.
+type LazyTraceContext struct {
+	TraceParent traceparent.TraceParent
+	TraceState  tracestate.Lazy
+}
+
+// LazyFromHeaders attempts to parse a `LazyTraceContext` from a set of headers.
+// As with `FromHeaders`, it is an error for the `traceparent` header to be invalid, but the `tracestate` header(s)
+// are only validated if `TraceState.Validate` is called.
+func LazyFromHeaders(headers http.Header) (LazyTraceContext, error) {
+	return LazyFromHeadersWithOptions(headers, Options{})
+}
+
+// LazyFromHeadersWithOptions attempts to parse a `LazyTraceContext` from a set of headers, as `LazyFromHeaders` does,
+// handling multiple `traceparent` values as configured by the `Options`.
+func LazyFromHeadersWithOptions(headers http.Header, opts Options) (LazyTraceContext, error) {
+	var tc LazyTraceContext
+
+	var err error
+	if tc.TraceParent, err = traceParentFromHeaders(headers, opts); err != nil {
+		return tc, err
+	}
+
+	tc.TraceState = tracestate.NewLazy(joinedTraceState(headers))
+	return tc, nil
+}
+
+// TraceContext parses the `tracestate` if necessary and returns the equivalent `TraceContext`,
+// with an empty `TraceState` if the `tracestate` is invalid, as `FromHeaders` would return.
+func (tc *LazyTraceContext) TraceContext() TraceContext {
+	return TraceContext{
+		TraceParent: tc.TraceParent,
+		TraceState:  tc.TraceState.TraceState(),
+	}
+}
+
+// SetHeaders sets the `traceparent` and `tracestate` headers based on the `LazyTraceContext`'s fields.
+// The `tracestate` header is set to the value that was received unless it has been modified, and is removed if it is empty.
+// Unlike `FromHeaders` followed by `TraceContext.SetHeaders`, this forwards an invalid `tracestate` as it was received,
+// as checking it would require parsing it. To drop it instead, replace the `TraceState` with an empty `tracestate.Lazy`
+// if `TraceState.Validate` returns an error.
+func (tc *LazyTraceContext) SetHeaders(headers http.Header) {
+	headers.Set(traceParentHeader, tc.TraceParent.String())
+	traceState := tc.TraceState.String()
+	if traceState == "" {
+		headers.Del(traceStateHeader)
+		return
+	}
+	headers.Set(traceStateHeader, traceState)
+}
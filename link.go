@@ -0,0 +1,195 @@
+package tracecontext
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+)
+
+const (
+	// maxLinksValueLength is the maximum length of a tracestate list member value.
+	maxLinksValueLength = 256
+
+	linksDelimiter = ";"
+)
+
+var (
+	// ErrInvalidLinks occurs when a value produced by `LinksValue` cannot be decoded.
+	ErrInvalidLinks = errors.New("tracecontext: Invalid links value")
+)
+
+// Link relates a span to a span in another trace, e.g., the caller's span when the trace was restarted,
+// or one of several parents of a span that aggregates work from many traces.
+type Link struct {
+	TraceParent traceparent.TraceParent
+	TraceState  tracestate.TraceState
+	// Attributes describe the relationship, e.g., why the trace was restarted.
+	Attributes map[string]string
+}
+
+// Link returns a `Link` to the span that the `TraceContext` identifies, with the given attributes, which may be nil.
+func (tc TraceContext) Link(attributes map[string]string) Link {
+	return Link{
+		TraceParent: tc.TraceParent,
+		TraceState:  tc.TraceState,
+		Attributes:  attributes,
+	}
+}
+
+// LinkFromHeaders returns a `Link` to the caller's span, for use when the trace is restarted because `FromHeaders` failed
+// or the caller is not trusted. The headers are parsed leniently, taking the first of multiple `traceparent` values
+// and accepting uppercase hex and surrounding whitespace, so that as many callers as possible can be linked.
+// It returns false if no link can be recovered, e.g., if the `traceparent` header is missing or its trace ID is all zeroes.
+func LinkFromHeaders(headers http.Header, attributes map[string]string) (Link, bool) {
+	tc, err := FromHeadersWithOptions(headers, Options{
+		MultipleHeaders: TakeFirst,
+		CombinedValues:  TakeFirst,
+		Normalizer:      &traceparent.Normalizer{},
+	})
+	if err != nil {
+		return Link{}, false
+	}
+	return tc.Link(attributes), true
+}
+
+// LinksFromBatch returns a `Link` to the span that sent each message in a batch, given the headers of each message,
+// as recovered by `LinkFromHeaders`. Messages without a recoverable trace context are skipped,
+// and messages sent from the same span result in a single link.
+func LinksFromBatch(headers []http.Header) []Link {
+	var links []Link
+	seen := make(map[traceparent.SpanID][]traceparent.TraceID)
+	for _, h := range headers {
+		link, ok := LinkFromHeaders(h, nil)
+		if !ok {
+			continue
+		}
+
+		tp := link.TraceParent
+		if containsTraceID(seen[tp.SpanID], tp.TraceID) {
+			continue
+		}
+		seen[tp.SpanID] = append(seen[tp.SpanID], tp.TraceID)
+		links = append(links, link)
+	}
+	return links
+}
+
+func containsTraceID(ids []traceparent.TraceID, id traceparent.TraceID) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
+
+// LinksValue encodes the `TraceParent`s of as many links as fit in a single tracestate list member value,
+// e.g., for a vendor to propagate a fan-in span's parents, and returns the number of links encoded.
+// Each link is encoded as `traceid-spanid-flags`, separated by `;`. Trace states and attributes are not encoded.
+func LinksValue(links []Link) (string, int) {
+	var b strings.Builder
+	n := 0
+	for _, link := range links {
+		tp := link.TraceParent
+		encoded := fmt.Sprintf("%s-%s-%s", tp.TraceID, tp.SpanID, tp.Flags)
+		if b.Len() > 0 {
+			encoded = linksDelimiter + encoded
+		}
+		if b.Len()+len(encoded) > maxLinksValueLength {
+			break
+		}
+		b.WriteString(encoded)
+		n++
+	}
+	return b.String(), n
+}
+
+// ParseLinksValue decodes the links encoded by `LinksValue`.
+// It returns `ErrInvalidLinks` if any link is incorrectly formatted or invalid.
+func ParseLinksValue(value string) ([]Link, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var links []Link
+	for _, encoded := range strings.Split(value, linksDelimiter) {
+		tp, err := traceparent.ParseString(fmt.Sprintf("%02x-%s", traceparent.Version, encoded))
+		if err != nil {
+			return nil, ErrInvalidLinks
+		}
+		links = append(links, Link{TraceParent: tp})
+	}
+	return links, nil
+}
+
+// String encodes the `Link` in logfmt, e.g., `traceparent=00-...-01 tracestate="a=1,b=2" reason=restarted`,
+// with attributes sorted by key. The `tracestate` is omitted if it is empty.
+func (l Link) String() string {
+	fields := []string{"traceparent=" + l.TraceParent.String()}
+	if len(l.TraceState) > 0 {
+		fields = append(fields, "tracestate="+logfmtValue(l.TraceState.String()))
+	}
+
+	keys := make([]string, 0, len(l.Attributes))
+	for key := range l.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fields = append(fields, logfmtValue(key)+"="+logfmtValue(l.Attributes[key]))
+	}
+
+	return strings.Join(fields, " ")
+}
+
+func logfmtValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+type jsonLink struct {
+	TraceParent traceparent.TraceParent `json:"traceparent"`
+	TraceState  string                  `json:"tracestate,omitempty"`
+	Attributes  map[string]string       `json:"attributes,omitempty"`
+}
+
+// MarshalJSON implements `json.Marshaler`, encoding the `Link` as a JSON object with `traceparent`, `tracestate` and `attributes` fields.
+func (l Link) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonLink{
+		TraceParent: l.TraceParent,
+		TraceState:  l.TraceState.String(),
+		Attributes:  l.Attributes,
+	})
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`, decoding the form produced by `MarshalJSON`.
+// As with `TraceContext`, it is an error for the `traceparent` to be missing or invalid, but an invalid `tracestate` results in an empty `TraceState`.
+func (l *Link) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	var tc TraceContext
+	if err := json.Unmarshal(data, &tc); err != nil {
+		return err
+	}
+	var raw struct {
+		Attributes map[string]string `json:"attributes"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*l = tc.Link(raw.Attributes)
+	return nil
+}
@@ -0,0 +1,183 @@
+package logscan
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+)
+
+const (
+	traceParentKey = "traceparent"
+	traceStateKey  = "tracestate"
+
+	maxLineBytes = 1 << 20
+)
+
+var (
+	// traceParentRe matches `traceparent`-shaped strings in any case, so that uppercase values are reported as invalid rather than ignored.
+	// Any `-suffix` after the flags is included, so that the parser rejects it for version 00 rather than it being cut off.
+	traceParentRe = regexp.MustCompile(`\b[0-9a-fA-F]{2}-[0-9a-fA-F]{32}-[0-9a-fA-F]{16}-[0-9a-fA-F]{2}(?:-[^\s"',;]*|\b)`)
+	// traceStateRe matches a `tracestate` key followed by a quoted value, or an unquoted value that may contain spaces after commas.
+	traceStateRe = regexp.MustCompile(`(?i)\btracestate["']?\s*[:=]\s*(?:"([^"]*)"|'([^']*)'|([^\s"',]+(?:,\s*[^\s"',]+)*))`)
+)
+
+// Trace aggregates the valid trace context found for a single trace ID.
+type Trace struct {
+	TraceID traceparent.TraceID `json:"trace_id"`
+	// Count is the number of valid `traceparent` values found with the trace ID.
+	Count int `json:"count"`
+	// SpanIDs are the distinct span IDs found with the trace ID, in the order in which they were first found.
+	SpanIDs []traceparent.SpanID `json:"span_ids"`
+	// TraceStates are the distinct valid `tracestate` values found on the same lines as the trace ID, in the order in which they were first found.
+	TraceStates []string `json:"tracestates,omitempty"`
+}
+
+// Report aggregates the trace context found in a text stream.
+type Report struct {
+	// Lines is the number of lines scanned.
+	Lines int `json:"lines"`
+	// Traces are grouped by trace ID, in the order in which they were first found.
+	Traces []*Trace `json:"traces"`
+	// InvalidTraceParents counts the invalid `traceparent` values found, keyed by the parser's error message.
+	InvalidTraceParents map[string]int `json:"invalid_traceparents"`
+	// InvalidTraceStates counts the invalid `tracestate` values found, keyed by the parser's error message.
+	InvalidTraceStates map[string]int `json:"invalid_tracestates"`
+
+	traces map[traceparent.TraceID]*Trace
+}
+
+// NewReport returns an empty `Report`.
+func NewReport() *Report {
+	return &Report{
+		InvalidTraceParents: make(map[string]int),
+		InvalidTraceStates:  make(map[string]int),
+		traces:              make(map[traceparent.TraceID]*Trace),
+	}
+}
+
+// Scan returns a `Report` of the trace context found in each line read from r.
+func Scan(r io.Reader) (*Report, error) {
+	report := NewReport()
+	return report, report.Scan(r)
+}
+
+// Scan adds the trace context found in each line read from r to the `Report`.
+func (r *Report) Scan(reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	for scanner.Scan() {
+		r.ScanLine(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// ScanLine adds the trace context found in a single line to the `Report`.
+//
+// A line that holds a JSON object is searched for `traceparent` and `tracestate` fields at any depth, with keys matched case-insensitively,
+// and for `traceparent`-shaped strings in any other string field.
+// Any other line is searched for `traceparent`-shaped strings and for `tracestate` values that follow a `tracestate` key, e.g., `tracestate: a=1,b=2`.
+//
+// Each `tracestate` value is attributed to the trace of the first valid `traceparent` on the same line, if any.
+func (r *Report) ScanLine(line string) {
+	r.Lines++
+
+	var traceParents, traceStates []string
+	var object map[string]interface{}
+	if strings.HasPrefix(strings.TrimSpace(line), "{") && json.Unmarshal([]byte(line), &object) == nil {
+		traceParents, traceStates = extractJSON(object, "", nil, nil)
+	} else {
+		traceParents, traceStates = extractText(line)
+	}
+
+	var trace *Trace
+	for _, s := range traceParents {
+		tp, err := traceparent.ParseString(s)
+		if err != nil {
+			r.InvalidTraceParents[err.Error()]++
+			continue
+		}
+		t := r.add(tp)
+		if trace == nil {
+			trace = t
+		}
+	}
+
+	for _, s := range traceStates {
+		if _, err := tracestate.ParseString(s); err != nil {
+			r.InvalidTraceStates[err.Error()]++
+			continue
+		}
+		if trace != nil && !contains(trace.TraceStates, s) {
+			trace.TraceStates = append(trace.TraceStates, s)
+		}
+	}
+}
+
+func (r *Report) add(tp traceparent.TraceParent) *Trace {
+	t, ok := r.traces[tp.TraceID]
+	if !ok {
+		t = &Trace{TraceID: tp.TraceID}
+		r.traces[tp.TraceID] = t
+		r.Traces = append(r.Traces, t)
+	}
+
+	t.Count++
+	for _, spanID := range t.SpanIDs {
+		if spanID == tp.SpanID {
+			return t
+		}
+	}
+	t.SpanIDs = append(t.SpanIDs, tp.SpanID)
+	return t
+}
+
+func extractText(line string) (traceParents, traceStates []string) {
+	traceParents = traceParentRe.FindAllString(line, -1)
+	for _, matches := range traceStateRe.FindAllStringSubmatch(line, -1) {
+		traceStates = append(traceStates, matches[1]+matches[2]+matches[3])
+	}
+	return traceParents, traceStates
+}
+
+func extractJSON(v interface{}, key string, traceParents, traceStates []string) ([]string, []string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			traceParents, traceStates = extractJSON(v[k], k, traceParents, traceStates)
+		}
+	case []interface{}:
+		for _, value := range v {
+			traceParents, traceStates = extractJSON(value, key, traceParents, traceStates)
+		}
+	case string:
+		switch {
+		case strings.EqualFold(key, traceParentKey):
+			traceParents = append(traceParents, v)
+		case strings.EqualFold(key, traceStateKey):
+			traceStates = append(traceStates, v)
+		default:
+			traceParents = append(traceParents, traceParentRe.FindAllString(v, -1)...)
+		}
+	}
+	return traceParents, traceStates
+}
+
+func contains(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
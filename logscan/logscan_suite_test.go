@@ -0,0 +1,82 @@
+package logscan_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/lightstep/tracecontext.go/logscan"
+	"github.com/lightstep/tracecontext.go/traceparent"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestLogscan(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Logscan Suite")
+}
+
+func mustTraceID(s string) traceparent.TraceID {
+	id, err := traceparent.ParseTraceID(s)
+	Expect(err).NotTo(HaveOccurred())
+	return id
+}
+
+func mustSpanID(s string) traceparent.SpanID {
+	id, err := traceparent.ParseSpanID(s)
+	Expect(err).NotTo(HaveOccurred())
+	return id
+}
+
+var _ = Describe(".Scan", func() {
+	It("groups valid trace context by trace ID", func() {
+		report, err := Scan(strings.NewReader(strings.Join([]string{
+			`10.0.0.1 - - "GET / HTTP/1.1" 200 traceparent="00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01" tracestate="congo=t61rcWkgMzE"`,
+			`no trace context here`,
+			`{"level":"info","headers":{"Traceparent":"00-0af7651916cd43dd8448eb211c80319c-00f067aa0ba902b7-00","TraceState":"rojo=00f067aa0ba902b7, congo=t61rcWkgMzE"}}`,
+			`parent 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01 tracestate: congo=t61rcWkgMzE`,
+			`retrying 00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01 tracestate: congo=t61rcWkgMzE`,
+		}, "\n")))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.Lines).To(Equal(5))
+		Expect(report.Traces).To(Equal([]*Trace{
+			{
+				TraceID:     mustTraceID("0af7651916cd43dd8448eb211c80319c"),
+				Count:       3,
+				SpanIDs:     []traceparent.SpanID{mustSpanID("b7ad6b7169203331"), mustSpanID("00f067aa0ba902b7")},
+				TraceStates: []string{"congo=t61rcWkgMzE", "rojo=00f067aa0ba902b7, congo=t61rcWkgMzE"},
+			},
+			{
+				TraceID:     mustTraceID("4bf92f3577b34da6a3ce929d0e0e4736"),
+				Count:       1,
+				SpanIDs:     []traceparent.SpanID{mustSpanID("00f067aa0ba902b7")},
+				TraceStates: []string{"congo=t61rcWkgMzE"},
+			},
+		}))
+		Expect(report.InvalidTraceParents).To(BeEmpty())
+		Expect(report.InvalidTraceStates).To(BeEmpty())
+	})
+
+	It("counts invalid values by error", func() {
+		report, err := Scan(strings.NewReader(strings.Join([]string{
+			`traceparent: 00-00000000000000000000000000000000-b7ad6b7169203331-01`,
+			`traceparent: 00-0AF7651916CD43DD8448EB211C80319C-B7AD6B7169203331-01, tracestate: a=1,a=2`,
+			`traceparent: ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01`,
+			`{"traceparent":"00-0af7651916cd43dd8448eb211c80319c","tracestate":"Invalid=1"}`,
+			`tracestate="ok=1"`,
+			`traceparent=00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-garbage, status=200`,
+		}, "\n")))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.Traces).To(BeEmpty())
+		Expect(report.InvalidTraceParents).To(Equal(map[string]int{
+			traceparent.ErrInvalidTraceID.Error(): 1,
+			traceparent.ErrInvalidFormat.Error():  3,
+			traceparent.ErrInvalidVersion.Error(): 1,
+		}))
+		Expect(report.InvalidTraceStates).To(Equal(map[string]int{
+			"tracecontext: Duplicate list member key in tracestate": 1,
+			"tracecontext: Invalid tracestate list member":          1,
+		}))
+	})
+})
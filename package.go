@@ -19,8 +19,45 @@ const (
 var (
 	// ErrInvalidHeadersMultipleTraceParent occurs when there are multiple `traceparent` headers present.
 	ErrInvalidHeadersMultipleTraceParent = errors.New("tracecontext: Multiple traceparent headers")
+	// ErrInvalidHeadersCombinedTraceParent occurs when a single `traceparent` header contains multiple comma-joined values.
+	ErrInvalidHeadersCombinedTraceParent = errors.New("tracecontext: Multiple comma-joined values in traceparent header")
+	// ErrInvalidHeadersConflictingTraceParent occurs when multiple `traceparent` values are present and required to be identical, but are not.
+	ErrInvalidHeadersConflictingTraceParent = errors.New("tracecontext: Conflicting traceparent values")
 )
 
+// MultipleTraceParentPolicy determines how multiple `traceparent` values are handled.
+type MultipleTraceParentPolicy int
+
+const (
+	// RejectMultiple rejects multiple values with an error. This is the default.
+	RejectMultiple MultipleTraceParentPolicy = iota
+	// TakeFirst uses the first value and ignores the rest.
+	TakeFirst
+	// TakeIfIdentical uses the first value if all values are identical, and otherwise rejects them
+	// with `ErrInvalidHeadersConflictingTraceParent`.
+	TakeIfIdentical
+)
+
+// Options configure how `FromHeadersWithOptions` extracts a `TraceContext`.
+type Options struct {
+	// MultipleHeaders determines how multiple `traceparent` header lines are handled.
+	// When rejected, `ErrInvalidHeadersMultipleTraceParent` is returned.
+	MultipleHeaders MultipleTraceParentPolicy
+	// CombinedValues determines how a single `traceparent` header line containing comma-joined values, as some proxies produce, is handled.
+	// When rejected, `ErrInvalidHeadersCombinedTraceParent` is returned, including for a trailing comma.
+	// Otherwise, whitespace around each value and empty values are ignored.
+	// Each header line is resolved to a single value before `MultipleHeaders` is applied.
+	CombinedValues MultipleTraceParentPolicy
+	// Normalizer, if set, is used to parse the `traceparent` value leniently, accepting uppercase hex and
+	// surrounding whitespace, and counting how often that was necessary.
+	// Use `traceparent.Normalize` on the header directly to identify individual non-compliant requests.
+	Normalizer *traceparent.Normalizer
+	// Fallback, if set, extracts the `TraceParent` from other headers when there is no `traceparent` header,
+	// e.g., those of another tracing system, such as `interop.CloudTraceFallback`. Its error is returned if it fails.
+	// The `tracestate` header is handled as usual.
+	Fallback func(headers http.Header) (traceparent.TraceParent, error)
+}
+
 var (
 	traceParentHeader = textproto.CanonicalMIMEHeaderKey("traceparent")
 	traceStateHeader  = textproto.CanonicalMIMEHeaderKey("tracestate")
@@ -38,24 +75,20 @@ type TraceContext struct {
 // It is considered an error for the `traceparent` header to be invalid, but not for the `tracestate` header(s) to be invalid.
 // If the `traceparent` header is valid and `tracestate` is not, a `TraceContext` with an empty `TraceState` will still be returned.
 func FromHeaders(headers http.Header) (TraceContext, error) {
-	var tc TraceContext
+	return FromHeadersWithOptions(headers, Options{})
+}
 
-	h := textproto.MIMEHeader(headers)
-	if len(h[traceParentHeader]) > 1 {
-		return tc, ErrInvalidHeadersMultipleTraceParent
-	}
+// FromHeadersWithOptions attempts to parse a TraceContext from a set of headers, as `FromHeaders` does,
+// handling multiple `traceparent` values as configured by the `Options`.
+func FromHeadersWithOptions(headers http.Header, opts Options) (TraceContext, error) {
+	var tc TraceContext
 
 	var err error
-	if tc.TraceParent, err = traceparent.ParseString(h.Get(traceParentHeader)); err != nil {
+	if tc.TraceParent, err = traceParentFromHeaders(headers, opts); err != nil {
 		return tc, err
 	}
 
-	var traceStates []string
-	for _, traceState := range h[traceStateHeader] {
-		traceStates = append(traceStates, traceState)
-	}
-
-	traceState, err := tracestate.ParseString(strings.Join(traceStates, ","))
+	traceState, err := tracestate.ParseString(joinedTraceState(headers))
 	if err == nil {
 		tc.TraceState = traceState
 	}
@@ -63,6 +96,78 @@ func FromHeaders(headers http.Header) (TraceContext, error) {
 	return tc, nil
 }
 
+func traceParentFromHeaders(headers http.Header, opts Options) (traceparent.TraceParent, error) {
+	h := textproto.MIMEHeader(headers)
+	if len(h[traceParentHeader]) == 0 && opts.Fallback != nil {
+		return opts.Fallback(headers)
+	}
+
+	var traceParents []string
+	for _, line := range h[traceParentHeader] {
+		traceParent, err := resolveTraceParents(splitCombined(line, opts.CombinedValues), opts.CombinedValues, ErrInvalidHeadersCombinedTraceParent)
+		if err != nil {
+			return traceparent.TraceParent{}, err
+		}
+		traceParents = append(traceParents, traceParent)
+	}
+
+	traceParent, err := resolveTraceParents(traceParents, opts.MultipleHeaders, ErrInvalidHeadersMultipleTraceParent)
+	if err != nil {
+		return traceparent.TraceParent{}, err
+	}
+
+	if opts.Normalizer != nil {
+		return opts.Normalizer.ParseString(traceParent)
+	}
+	return traceparent.ParseString(traceParent)
+}
+
+// joinedTraceState returns the values of the `tracestate` headers joined by commas, as the spec requires.
+func joinedTraceState(headers http.Header) string {
+	return strings.Join(textproto.MIMEHeader(headers)[traceStateHeader], ",")
+}
+
+// splitCombined splits a header line into its comma-joined values.
+// Under the default `RejectMultiple` policy, the values are kept as they are, so that a trailing comma is still rejected.
+// Otherwise, if the line has a comma, surrounding whitespace and empty values are ignored.
+// A single value is always kept as it is, so that surrounding whitespace is only accepted if the `Normalizer` removes it.
+func splitCombined(line string, policy MultipleTraceParentPolicy) []string {
+	if policy == RejectMultiple || !strings.Contains(line, ",") {
+		return strings.Split(line, ",")
+	}
+
+	var values []string
+	for _, value := range strings.Split(line, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func resolveTraceParents(values []string, policy MultipleTraceParentPolicy, errMultiple error) (string, error) {
+	if len(values) == 0 {
+		return "", nil
+	}
+	if len(values) == 1 {
+		return values[0], nil
+	}
+
+	switch policy {
+	case TakeFirst:
+		return values[0], nil
+	case TakeIfIdentical:
+		for _, value := range values[1:] {
+			if value != values[0] {
+				return "", ErrInvalidHeadersConflictingTraceParent
+			}
+		}
+		return values[0], nil
+	default:
+		return "", errMultiple
+	}
+}
+
 // SetHeaders sets the `traceparent` and `tracestate` headers based on the `TraceContext`'s fields.
 func (tc TraceContext) SetHeaders(headers http.Header) {
 	headers.Set(traceParentHeader, tc.TraceParent.String())
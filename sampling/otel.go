@@ -0,0 +1,265 @@
+package sampling
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+)
+
+const (
+	// OTelVendor is the `tracestate` key under which OpenTelemetry stores its sampling fields.
+	OTelVendor = "ot"
+
+	// AlwaysSample is the `Threshold` at which every trace is sampled, i.e., a probability of 1.
+	AlwaysSample Threshold = 0
+	// NeverSample is the `Threshold` at which no trace is sampled, i.e., a probability of 0.
+	// It cannot be encoded in a `th` field.
+	NeverSample Threshold = 1 << numRandomBits
+
+	// MaxRandomness is the largest possible `Randomness` value.
+	MaxRandomness Randomness = 1<<numRandomBits - 1
+)
+
+var (
+	// ErrInvalidThreshold occurs if the `th` field is not 1 to 14 lowercase hex digits.
+	ErrInvalidThreshold = errors.New("tracecontext: Invalid OpenTelemetry sampling threshold")
+	// ErrInvalidRandomness occurs if the `rv` field is not exactly 14 lowercase hex digits.
+	ErrInvalidRandomness = errors.New("tracecontext: Invalid OpenTelemetry sampling randomness")
+	// ErrInvalidProbability occurs if a sampling probability is not within [0, 1].
+	ErrInvalidProbability = errors.New("tracecontext: Invalid sampling probability")
+	// ErrNoRandomness occurs if neither an `rv` field nor the traceparent random flag provides randomness for a trace.
+	ErrNoRandomness = errors.New("tracecontext: No sampling randomness available for trace")
+)
+
+const (
+	numRandomBits   = 56
+	numRandomDigits = numRandomBits / 4
+
+	thresholdKey  = "th"
+	randomnessKey = "rv"
+)
+
+var (
+	thresholdRe  = regexp.MustCompile(`^[0-9a-f]{1,14}$`)
+	randomnessRe = regexp.MustCompile(`^[0-9a-f]{14}$`)
+)
+
+// Threshold is a 56-bit rejection threshold: a trace is sampled if its `Randomness` is greater than or equal to it.
+type Threshold uint64
+
+// ThresholdForProbability returns the `Threshold` that samples traces with the given probability.
+// It returns `ErrInvalidProbability` if the probability is not within [0, 1].
+func ThresholdForProbability(probability float64) (Threshold, error) {
+	if math.IsNaN(probability) || probability < 0 || probability > 1 {
+		return NeverSample, ErrInvalidProbability
+	}
+	return Threshold(math.Round((1 - probability) * float64(NeverSample))), nil
+}
+
+// ParseThreshold attempts to decode a `Threshold` from the value of a `th` field.
+// Omitted trailing digits are treated as zeroes.
+func ParseThreshold(s string) (Threshold, error) {
+	if !thresholdRe.MatchString(s) {
+		return NeverSample, ErrInvalidThreshold
+	}
+	s += strings.Repeat("0", numRandomDigits-len(s))
+	t, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return NeverSample, ErrInvalidThreshold
+	}
+	return Threshold(t), nil
+}
+
+// Probability returns the probability with which the `Threshold` samples traces.
+func (t Threshold) Probability() float64 {
+	if t >= NeverSample {
+		return 0
+	}
+	return 1 - float64(t)/float64(NeverSample)
+}
+
+// ShouldSample reports whether a trace with the given `Randomness` is sampled at this `Threshold`.
+func (t Threshold) ShouldSample(r Randomness) bool {
+	return uint64(r) >= uint64(t)
+}
+
+// String encodes the `Threshold` as the value of a `th` field, i.e., in hex without trailing zeroes.
+// The string is invalid for `NeverSample`, which cannot be encoded.
+func (t Threshold) String() string {
+	s := strings.TrimRight(fmt.Sprintf("%014x", uint64(t)), "0")
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+// Randomness is a 56-bit random value associated with a trace, used to make consistent sampling decisions.
+type Randomness uint64
+
+// ParseRandomness attempts to decode a `Randomness` from the value of an `rv` field.
+func ParseRandomness(s string) (Randomness, error) {
+	if !randomnessRe.MatchString(s) {
+		return 0, ErrInvalidRandomness
+	}
+	r, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, ErrInvalidRandomness
+	}
+	return Randomness(r), nil
+}
+
+// RandomnessFromTraceID returns the `Randomness` encoded in the rightmost 7 bytes of a trace ID.
+func RandomnessFromTraceID(traceID traceparent.TraceID) Randomness {
+	var r uint64
+	for _, b := range traceID[16-numRandomBits/8:] {
+		r = r<<8 | uint64(b)
+	}
+	return Randomness(r)
+}
+
+// String encodes the `Randomness` as the value of an `rv` field, i.e., as exactly 14 hex digits.
+func (r Randomness) String() string {
+	return fmt.Sprintf("%014x", uint64(r)&uint64(MaxRandomness))
+}
+
+// OTelState represents the sampling-related fields of the OpenTelemetry `ot` tracestate list member.
+type OTelState struct {
+	// Threshold is the rejection threshold with which the trace was sampled, if `HasThreshold` is true.
+	Threshold    Threshold
+	HasThreshold bool
+	// Randomness is the explicit randomness of the trace, if `HasRandomness` is true.
+	Randomness    Randomness
+	HasRandomness bool
+	// Fields holds any other fields of the `ot` list member, which are preserved as-is.
+	Fields tracestate.Fields
+}
+
+// FromTraceState decodes the `OTelState` from the `ot` member of a `TraceState`.
+// A `TraceState` without an `ot` member results in an empty `OTelState`.
+func FromTraceState(ts tracestate.TraceState) (OTelState, error) {
+	var s OTelState
+
+	m, ok := ts.GetKey(OTelVendor)
+	if !ok {
+		return s, nil
+	}
+
+	fields, err := tracestate.ParseFields(m.Value)
+	if err != nil {
+		return s, err
+	}
+
+	for _, f := range fields {
+		switch f.Key {
+		case thresholdKey:
+			if s.Threshold, err = ParseThreshold(f.Value); err != nil {
+				return OTelState{}, err
+			}
+			s.HasThreshold = true
+		case randomnessKey:
+			if s.Randomness, err = ParseRandomness(f.Value); err != nil {
+				return OTelState{}, err
+			}
+			s.HasRandomness = true
+		default:
+			s.Fields = append(s.Fields, f)
+		}
+	}
+
+	return s, nil
+}
+
+// ApplyTo returns a copy of the `TraceState` with its `ot` member updated to reflect the `OTelState`,
+// moved to the front of the list. The `ot` member is removed if the `OTelState` has no fields.
+func (s OTelState) ApplyTo(ts tracestate.TraceState) (tracestate.TraceState, error) {
+	var fields tracestate.Fields
+	if s.HasThreshold && s.Threshold < NeverSample {
+		fields = append(fields, tracestate.Field{Key: thresholdKey, Value: s.Threshold.String()})
+	}
+	if s.HasRandomness {
+		fields = append(fields, tracestate.Field{Key: randomnessKey, Value: s.Randomness.String()})
+	}
+	fields = append(fields, s.Fields...)
+
+	if len(fields) == 0 {
+		return ts.DeleteKey(OTelVendor), nil
+	}
+	if err := fields.Validate(); err != nil {
+		return ts, err
+	}
+
+	return ts.Set(tracestate.Member{
+		SystemID: OTelVendor,
+		Value:    fields.String(),
+	})
+}
+
+// TraceRandomness returns the `Randomness` of the trace: the explicit `rv` field if present,
+// otherwise the rightmost 7 bytes of the trace ID if the `TraceParent`'s random flag is set.
+// It returns `ErrNoRandomness` if neither is available.
+func (s OTelState) TraceRandomness(tp traceparent.TraceParent) (Randomness, error) {
+	if s.HasRandomness {
+		return s.Randomness, nil
+	}
+	if tp.Flags.Random {
+		return RandomnessFromTraceID(tp.TraceID), nil
+	}
+	return 0, ErrNoRandomness
+}
+
+// ShouldSample reports whether the trace identified by the `TraceParent` and `TraceState` is sampled
+// with the given probability.
+func ShouldSample(tp traceparent.TraceParent, ts tracestate.TraceState, probability float64) (bool, error) {
+	t, err := ThresholdForProbability(probability)
+	if err != nil {
+		return false, err
+	}
+
+	s, err := FromTraceState(ts)
+	if err != nil {
+		return false, err
+	}
+
+	r, err := s.TraceRandomness(tp)
+	if err != nil {
+		return false, err
+	}
+
+	return t.ShouldSample(r), nil
+}
+
+// Sample makes a sampling decision for the trace with the given probability, and returns copies of the
+// `TraceParent` and `TraceState` updated accordingly: the recorded flag is set to the decision,
+// and the `th` field records the threshold if the trace was sampled, or is removed if it was not.
+func Sample(tp traceparent.TraceParent, ts tracestate.TraceState, probability float64) (traceparent.TraceParent, tracestate.TraceState, error) {
+	t, err := ThresholdForProbability(probability)
+	if err != nil {
+		return tp, ts, err
+	}
+
+	s, err := FromTraceState(ts)
+	if err != nil {
+		return tp, ts, err
+	}
+
+	r, err := s.TraceRandomness(tp)
+	if err != nil {
+		return tp, ts, err
+	}
+
+	sampled := t.ShouldSample(r)
+	s.Threshold, s.HasThreshold = t, sampled
+
+	if ts, err = s.ApplyTo(ts); err != nil {
+		return tp, ts, err
+	}
+	tp.Flags.Recorded = sampled
+
+	return tp, ts, nil
+}
@@ -0,0 +1,140 @@
+package sampling
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/lightstep/tracecontext.go/traceparent"
+)
+
+// Sampler decides whether new spans should be recorded.
+type Sampler interface {
+	// Sample returns the `Flags` to use for a new span in the trace with the given trace ID.
+	// The parent is nil if the new span is the root of a new trace.
+	Sample(parent *traceparent.TraceParent, traceID traceparent.TraceID) traceparent.Flags
+}
+
+// SamplerFunc is an adapter that allows an ordinary function to be used as a `Sampler`.
+type SamplerFunc func(parent *traceparent.TraceParent, traceID traceparent.TraceID) traceparent.Flags
+
+// Sample calls f(parent, traceID).
+func (f SamplerFunc) Sample(parent *traceparent.TraceParent, traceID traceparent.TraceID) traceparent.Flags {
+	return f(parent, traceID)
+}
+
+var (
+	// Always is a `Sampler` that records every span.
+	Always Sampler = SamplerFunc(func(parent *traceparent.TraceParent, traceID traceparent.TraceID) traceparent.Flags {
+		return flags(parent, true)
+	})
+	// Never is a `Sampler` that records no spans.
+	Never Sampler = SamplerFunc(func(parent *traceparent.TraceParent, traceID traceparent.TraceID) traceparent.Flags {
+		return flags(parent, false)
+	})
+)
+
+// TraceIDRatio returns a `Sampler` that records the given fraction of traces.
+// The decision is deterministic: it compares the rightmost 7 bytes of the trace ID against the `Threshold`
+// for the fraction, so that every participant using the same fraction makes the same decision for a trace.
+// It returns `ErrInvalidProbability` if the fraction is not within [0, 1].
+func TraceIDRatio(fraction float64) (Sampler, error) {
+	t, err := ThresholdForProbability(fraction)
+	if err != nil {
+		return nil, err
+	}
+
+	return SamplerFunc(func(parent *traceparent.TraceParent, traceID traceparent.TraceID) traceparent.Flags {
+		return flags(parent, t.ShouldSample(RandomnessFromTraceID(traceID)))
+	}), nil
+}
+
+// ParentBased returns a `Sampler` that follows the parent's recorded flag, and defers to the root `Sampler`
+// for new traces.
+func ParentBased(root Sampler) Sampler {
+	return SamplerFunc(func(parent *traceparent.TraceParent, traceID traceparent.TraceID) traceparent.Flags {
+		if parent == nil {
+			return root.Sample(parent, traceID)
+		}
+		return parent.Flags
+	})
+}
+
+// RateLimited returns a `Sampler` that records at most the given number of traces per second on average,
+// allowing short bursts of up to one second's worth of traces.
+// The clock is used to measure elapsed time; if it is nil, `time.Now` is used.
+// A rate that is not positive, including NaN, records no traces, as `Never` does.
+func RateLimited(tracesPerSecond float64, clock func() time.Time) Sampler {
+	if !(tracesPerSecond > 0) {
+		return Never
+	}
+	if clock == nil {
+		clock = time.Now
+	}
+
+	maxBalance := math.Max(tracesPerSecond, 1)
+	return &rateLimited{
+		tracesPerSecond: tracesPerSecond,
+		maxBalance:      maxBalance,
+		balance:         maxBalance,
+		clock:           clock,
+		last:            clock(),
+	}
+}
+
+type rateLimited struct {
+	tracesPerSecond float64
+	maxBalance      float64
+	clock           func() time.Time
+
+	mu      sync.Mutex
+	balance float64
+	last    time.Time
+}
+
+func (r *rateLimited) Sample(parent *traceparent.TraceParent, traceID traceparent.TraceID) traceparent.Flags {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock()
+	if elapsed := now.Sub(r.last); elapsed > 0 {
+		r.balance = math.Min(r.maxBalance, r.balance+elapsed.Seconds()*r.tracesPerSecond)
+		r.last = now
+	}
+
+	if r.balance < 1 {
+		return flags(parent, false)
+	}
+	r.balance--
+	return flags(parent, true)
+}
+
+// NewRoot returns a `TraceParent` for the root span of a new trace, with `Flags` decided by the `Sampler`.
+// The random flag is left unset; callers that generated the trace ID randomly may set it.
+func NewRoot(s Sampler, traceID traceparent.TraceID, spanID traceparent.SpanID) traceparent.TraceParent {
+	return traceparent.TraceParent{
+		Version: traceparent.Version,
+		TraceID: traceID,
+		SpanID:  spanID,
+		Flags:   s.Sample(nil, traceID),
+	}
+}
+
+// NewChild returns a `TraceParent` for a new child span of the parent, with `Flags` decided by the `Sampler`.
+func NewChild(s Sampler, parent traceparent.TraceParent, spanID traceparent.SpanID) traceparent.TraceParent {
+	return traceparent.TraceParent{
+		Version: traceparent.Version,
+		TraceID: parent.TraceID,
+		SpanID:  spanID,
+		Flags:   s.Sample(&parent, parent.TraceID),
+	}
+}
+
+func flags(parent *traceparent.TraceParent, recorded bool) traceparent.Flags {
+	var f traceparent.Flags
+	if parent != nil {
+		f = parent.Flags
+	}
+	f.Recorded = recorded
+	return f
+}
@@ -0,0 +1,133 @@
+package sampling_test
+
+import (
+	"fmt"
+	"math"
+	"testing/quick"
+	"time"
+
+	. "github.com/lightstep/tracecontext.go/sampling"
+	"github.com/lightstep/tracecontext.go/traceparent"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sampler", func() {
+	traceIDWithRandomness := func(b byte) [16]byte {
+		return [16]byte{0: 1, 9: b}
+	}
+
+	Describe("Always and Never", func() {
+		It("always and never record", func() {
+			quick.Check(func(traceID [16]byte) bool {
+				Expect(Always.Sample(nil, traceID).Recorded).To(BeTrue())
+				Expect(Never.Sample(nil, traceID).Recorded).To(BeFalse())
+				return true
+			}, nil)
+		})
+
+		It("preserve the parent's other flags", func() {
+			parent := &traceparent.TraceParent{Flags: traceparent.Flags{Recorded: true, Random: true}}
+			Expect(Never.Sample(parent, [16]byte{})).To(Equal(traceparent.Flags{Random: true}))
+		})
+	})
+
+	Describe("TraceIDRatio", func() {
+		It("records traces whose trace ID randomness is above the threshold", func() {
+			s, err := TraceIDRatio(0.25)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(s.Sample(nil, traceIDWithRandomness(0xbf)).Recorded).To(BeFalse())
+			Expect(s.Sample(nil, traceIDWithRandomness(0xc0)).Recorded).To(BeTrue())
+		})
+
+		It("ignores the leftmost 9 bytes of the trace ID", func() {
+			s, err := TraceIDRatio(0.5)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(s.Sample(nil, [16]byte{0: 0xff, 8: 0xff}).Recorded).To(BeFalse())
+		})
+
+		It("records approximately the given fraction of traces", func() {
+			s, err := TraceIDRatio(0.5)
+			Expect(err).NotTo(HaveOccurred())
+
+			recorded := 0
+			for i := 0; i < 256; i++ {
+				if s.Sample(nil, traceIDWithRandomness(byte(i))).Recorded {
+					recorded++
+				}
+			}
+			Expect(recorded).To(Equal(128))
+		})
+
+		It("errors if the fraction is out of range", func() {
+			_, err := TraceIDRatio(2)
+			Expect(err).To(MatchError("tracecontext: Invalid sampling probability"))
+		})
+	})
+
+	Describe("ParentBased", func() {
+		It("follows the parent and defers to the root sampler for new traces", func() {
+			s := ParentBased(Always)
+
+			Expect(s.Sample(nil, [16]byte{}).Recorded).To(BeTrue())
+			Expect(s.Sample(&traceparent.TraceParent{}, [16]byte{}).Recorded).To(BeFalse())
+
+			parent := traceparent.TraceParent{Flags: traceparent.Flags{Recorded: true}}
+			Expect(ParentBased(Never).Sample(&parent, [16]byte{}).Recorded).To(BeTrue())
+		})
+	})
+
+	Describe("RateLimited", func() {
+		It("records at most the given rate", func() {
+			now := time.Unix(0, 0)
+			s := RateLimited(2, func() time.Time { return now })
+
+			Expect(s.Sample(nil, [16]byte{}).Recorded).To(BeTrue())
+			Expect(s.Sample(nil, [16]byte{}).Recorded).To(BeTrue())
+			Expect(s.Sample(nil, [16]byte{}).Recorded).To(BeFalse())
+
+			now = now.Add(500 * time.Millisecond)
+			Expect(s.Sample(nil, [16]byte{}).Recorded).To(BeTrue())
+			Expect(s.Sample(nil, [16]byte{}).Recorded).To(BeFalse())
+
+			now = now.Add(time.Hour)
+			Expect(s.Sample(nil, [16]byte{}).Recorded).To(BeTrue())
+			Expect(s.Sample(nil, [16]byte{}).Recorded).To(BeTrue())
+			Expect(s.Sample(nil, [16]byte{}).Recorded).To(BeFalse())
+		})
+
+		It("allows fractional rates", func() {
+			now := time.Unix(0, 0)
+			s := RateLimited(0.5, func() time.Time { return now })
+
+			Expect(s.Sample(nil, [16]byte{}).Recorded).To(BeTrue())
+			now = now.Add(time.Second)
+			Expect(s.Sample(nil, [16]byte{}).Recorded).To(BeFalse())
+			now = now.Add(time.Second)
+			Expect(s.Sample(nil, [16]byte{}).Recorded).To(BeTrue())
+		})
+
+		It("records nothing for rates that are not positive", func() {
+			for _, rate := range []float64{0, -1, math.Inf(-1), math.NaN()} {
+				now := time.Unix(0, 0)
+				s := RateLimited(rate, func() time.Time { return now })
+
+				Expect(s.Sample(nil, [16]byte{}).Recorded).To(BeFalse(), fmt.Sprint(rate))
+				now = now.Add(time.Hour)
+				Expect(s.Sample(nil, [16]byte{}).Recorded).To(BeFalse(), fmt.Sprint(rate))
+			}
+		})
+	})
+})
+
+var _ = Describe(".NewRoot and .NewChild", func() {
+	It("start and continue a trace with sampled flags", func() {
+		root := NewRoot(Always, [16]byte{0: 1}, [8]byte{0: 1})
+		Expect(root.String()).To(Equal("00-01000000000000000000000000000000-0100000000000000-01"))
+
+		child := NewChild(ParentBased(Never), root, [8]byte{0: 2})
+		Expect(child.String()).To(Equal("00-01000000000000000000000000000000-0200000000000000-01"))
+	})
+})
@@ -0,0 +1,199 @@
+package sampling_test
+
+import (
+	"testing"
+	"testing/quick"
+
+	. "github.com/lightstep/tracecontext.go/sampling"
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSampling(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Sampling Suite")
+}
+
+var _ = Describe("Threshold", func() {
+	It("is derived from a probability", func() {
+		for probability, expected := range map[float64]string{
+			1:      "0",
+			0.5:    "8",
+			0.25:   "c",
+			0.75:   "4",
+			0.0625: "f",
+		} {
+			t, err := ThresholdForProbability(probability)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(t.String()).To(Equal(expected))
+			Expect(t.Probability()).To(Equal(probability))
+		}
+
+		t, err := ThresholdForProbability(0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t).To(Equal(NeverSample))
+		Expect(t.Probability()).To(BeZero())
+	})
+
+	It("errors if the probability is out of range", func() {
+		for _, probability := range []float64{-0.1, 1.1} {
+			_, err := ThresholdForProbability(probability)
+			Expect(err).To(MatchError("tracecontext: Invalid sampling probability"))
+		}
+	})
+
+	It("round-trips through its encoding", func() {
+		quick.Check(func(t uint64) bool {
+			threshold := Threshold(t % uint64(NeverSample))
+
+			parsed, err := ParseThreshold(threshold.String())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(Equal(threshold))
+
+			return true
+		}, nil)
+	})
+
+	It("errors if the encoding is invalid", func() {
+		for _, s := range []string{"", "G", "A", "123456789abcdef", "-1"} {
+			_, err := ParseThreshold(s)
+			Expect(err).To(MatchError("tracecontext: Invalid OpenTelemetry sampling threshold"))
+		}
+	})
+
+	It("samples randomness at or above the threshold", func() {
+		t, _ := ParseThreshold("8")
+		Expect(t.ShouldSample(0x7fffffffffffff)).To(BeFalse())
+		Expect(t.ShouldSample(0x80000000000000)).To(BeTrue())
+		Expect(AlwaysSample.ShouldSample(0)).To(BeTrue())
+		Expect(NeverSample.ShouldSample(MaxRandomness)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Randomness", func() {
+	It("requires exactly 14 hex digits", func() {
+		r, err := ParseRandomness("0123456789abcd")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r).To(Equal(Randomness(0x0123456789abcd)))
+		Expect(r.String()).To(Equal("0123456789abcd"))
+
+		for _, s := range []string{"123456789abcd", "0123456789abcde", "0123456789ABCD"} {
+			_, err := ParseRandomness(s)
+			Expect(err).To(MatchError("tracecontext: Invalid OpenTelemetry sampling randomness"))
+		}
+	})
+
+	It("is derived from the rightmost 7 bytes of the trace ID", func() {
+		traceID := [16]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 1, 2, 3, 4, 5, 6, 7}
+		Expect(RandomnessFromTraceID(traceID)).To(Equal(Randomness(0x01020304050607)))
+	})
+})
+
+var _ = Describe("OTelState", func() {
+	It("decodes and preserves the ot member", func() {
+		ts, err := tracestate.ParseString("other=x,ot=foo:bar;th:c;rv:0123456789abcd")
+		Expect(err).NotTo(HaveOccurred())
+
+		s, err := FromTraceState(ts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.HasThreshold).To(BeTrue())
+		Expect(s.Threshold.Probability()).To(Equal(0.25))
+		Expect(s.HasRandomness).To(BeTrue())
+		Expect(s.Randomness).To(Equal(Randomness(0x0123456789abcd)))
+		Expect(s.Fields).To(Equal(tracestate.Fields{{Key: "foo", Value: "bar"}}))
+
+		updated, err := s.ApplyTo(ts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.String()).To(Equal("ot=th:c;rv:0123456789abcd;foo:bar,other=x"))
+	})
+
+	It("returns an empty state without an ot member", func() {
+		s, err := FromTraceState(tracestate.TraceState{{SystemID: "other", Value: "x"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s).To(Equal(OTelState{}))
+
+		updated, err := s.ApplyTo(tracestate.TraceState{{SystemID: "ot", Value: "th:0"}, {SystemID: "other", Value: "x"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.String()).To(Equal("other=x"))
+	})
+
+	It("errors if a field is invalid", func() {
+		_, err := FromTraceState(tracestate.TraceState{{SystemID: "ot", Value: "th:xyz"}})
+		Expect(err).To(MatchError("tracecontext: Invalid OpenTelemetry sampling threshold"))
+
+		_, err = FromTraceState(tracestate.TraceState{{SystemID: "ot", Value: "rv:12"}})
+		Expect(err).To(MatchError("tracecontext: Invalid OpenTelemetry sampling randomness"))
+	})
+
+	It("prefers explicit randomness over the trace ID", func() {
+		tp := traceparent.TraceParent{
+			TraceID: [16]byte{15: 1},
+			Flags:   traceparent.Flags{Random: true},
+		}
+
+		r, err := OTelState{}.TraceRandomness(tp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r).To(Equal(Randomness(1)))
+
+		r, err = OTelState{Randomness: 2, HasRandomness: true}.TraceRandomness(tp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r).To(Equal(Randomness(2)))
+
+		tp.Flags.Random = false
+		_, err = OTelState{}.TraceRandomness(tp)
+		Expect(err).To(MatchError("tracecontext: No sampling randomness available for trace"))
+	})
+})
+
+var _ = Describe(".Sample", func() {
+	newTraceParent := func(randomness byte) traceparent.TraceParent {
+		return traceparent.TraceParent{
+			TraceID: [16]byte{0: 1, 9: randomness},
+			SpanID:  [8]byte{0: 1},
+			Flags:   traceparent.Flags{Random: true},
+		}
+	}
+
+	It("records the threshold for sampled traces", func() {
+		tp, ts, err := Sample(newTraceParent(0xc0), nil, 0.5)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tp.Flags.Recorded).To(BeTrue())
+		Expect(ts.String()).To(Equal("ot=th:8"))
+
+		sampled, err := ShouldSample(tp, ts, 0.5)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sampled).To(BeTrue())
+	})
+
+	It("removes the threshold for unsampled traces", func() {
+		ts := tracestate.TraceState{{SystemID: "ot", Value: "th:0;foo:bar"}}
+
+		tp, ts, err := Sample(newTraceParent(0x40), ts, 0.5)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tp.Flags.Recorded).To(BeFalse())
+		Expect(ts.String()).To(Equal("ot=foo:bar"))
+	})
+
+	It("makes consistent decisions across probabilities", func() {
+		quick.Check(func(randomness byte, a, b uint8) bool {
+			tp := newTraceParent(randomness)
+			lower, higher := float64(a)/255, float64(b)/255
+			if lower > higher {
+				lower, higher = higher, lower
+			}
+
+			sampledAtLower, err := ShouldSample(tp, nil, lower)
+			Expect(err).NotTo(HaveOccurred())
+			sampledAtHigher, err := ShouldSample(tp, nil, higher)
+			Expect(err).NotTo(HaveOccurred())
+
+			if sampledAtLower {
+				Expect(sampledAtHigher).To(BeTrue())
+			}
+
+			return true
+		}, nil)
+	})
+})
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lightstep/tracecontext.go/conformance"
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestServer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Server Suite")
+}
+
+type recordedRequest struct {
+	Header    http.Header
+	Arguments []interface{}
+}
+
+// harness stands in for the W3C test harness: it records the requests that the service makes to its callback endpoint.
+type harness struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []recordedRequest
+	status   int
+	delay    time.Duration
+}
+
+func newHarness() *harness {
+	h := &harness{status: http.StatusOK}
+	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var arguments []interface{}
+		b, _ := ioutil.ReadAll(req.Body)
+		json.Unmarshal(b, &arguments)
+
+		h.mu.Lock()
+		h.requests = append(h.requests, recordedRequest{Header: req.Header, Arguments: arguments})
+		status, delay := h.status, h.delay
+		h.mu.Unlock()
+
+		time.Sleep(delay)
+		w.WriteHeader(status)
+	}))
+	return h
+}
+
+func (h *harness) Requests() []recordedRequest {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]recordedRequest(nil), h.requests...)
+}
+
+var _ = Describe("service", func() {
+	const (
+		traceParent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+		traceState  = "foo=1,bar=2"
+	)
+
+	var (
+		h       *harness
+		service *httptest.Server
+	)
+
+	BeforeEach(func() {
+		h = newHarness()
+		service = httptest.NewServer(newService(nil, "lightstep", time.Second))
+	})
+
+	AfterEach(func() {
+		service.Close()
+		h.Close()
+	})
+
+	post := func(headers map[string]string, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, service.URL, strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		return resp
+	}
+
+	callbacks := func(n int) string {
+		var items []string
+		for i := 0; i < n; i++ {
+			items = append(items, fmt.Sprintf(`{"url":%q,"arguments":[{"n":%d}]}`, h.URL, i))
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	}
+
+	It("propagates the trace context to a new child span for each callback", func() {
+		resp := post(map[string]string{"traceparent": traceParent, "tracestate": traceState}, callbacks(3))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		requests := h.Requests()
+		Expect(requests).To(HaveLen(3))
+
+		spanIDs := make(map[string]bool)
+		var arguments []interface{}
+		for _, r := range requests {
+			tp, err := traceparent.ParseString(r.Header.Get("traceparent"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tp.TraceID.String()).To(Equal("0af7651916cd43dd8448eb211c80319c"))
+			Expect(tp.SpanID.String()).NotTo(Equal("b7ad6b7169203331"))
+			Expect(tp.Flags.Recorded).To(BeTrue())
+			spanIDs[tp.SpanID.String()] = true
+
+			Expect(r.Header.Get("tracestate")).To(Equal(fmt.Sprintf("lightstep=%s,%s", tp.SpanID, traceState)))
+
+			arguments = append(arguments, r.Arguments...)
+		}
+		Expect(spanIDs).To(HaveLen(3))
+		Expect(arguments).To(ConsistOf(
+			map[string]interface{}{"n": 0.0},
+			map[string]interface{}{"n": 1.0},
+			map[string]interface{}{"n": 2.0},
+		))
+	})
+
+	It("downgrades higher versions", func() {
+		post(map[string]string{"traceparent": "cc-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-extra"}, callbacks(1))
+
+		requests := h.Requests()
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Header.Get("traceparent")).To(HavePrefix("00-0af7651916cd43dd8448eb211c80319c-"))
+	})
+
+	It("restarts the trace and discards the tracestate if the traceparent is invalid", func() {
+		post(map[string]string{"traceparent": "00-00000000000000000000000000000000-b7ad6b7169203331-01", "tracestate": traceState}, callbacks(1))
+
+		requests := h.Requests()
+		Expect(requests).To(HaveLen(1))
+
+		tp, err := traceparent.ParseString(requests[0].Header.Get("traceparent"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tp.TraceID.IsValid()).To(BeTrue())
+		Expect(tp.Flags.Recorded).To(BeFalse())
+		Expect(requests[0].Header.Get("tracestate")).To(Equal("lightstep=" + tp.SpanID.String()))
+	})
+
+	It("sends no tracestate header if it has no list members to propagate", func() {
+		service.Close()
+		service = httptest.NewServer(newService(nil, "", time.Second))
+		post(map[string]string{"traceparent": traceParent}, callbacks(1))
+
+		requests := h.Requests()
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Header).NotTo(HaveKey("Tracestate"))
+	})
+
+	It("replaces its own list member and keeps within the member limit", func() {
+		var members []string
+		for i := 0; i < 32; i++ {
+			members = append(members, fmt.Sprintf("vendor%d=%d", i, i))
+		}
+		members[5] = "lightstep=0000000000000001"
+
+		post(map[string]string{"traceparent": traceParent, "tracestate": strings.Join(members, ",")}, callbacks(1))
+		requests := h.Requests()
+		Expect(requests).To(HaveLen(1))
+		ts, err := tracestate.ParseString(requests[0].Header.Get("tracestate"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ts).To(HaveLen(32))
+		Expect(ts[0].Key()).To(Equal("lightstep"))
+		Expect(ts[31].Key()).To(Equal("vendor31"))
+
+		members[5] = "vendor5=5"
+		post(map[string]string{"traceparent": traceParent, "tracestate": strings.Join(members, ",")}, callbacks(1))
+		requests = h.Requests()
+		Expect(requests).To(HaveLen(2))
+		ts, err = tracestate.ParseString(requests[1].Header.Get("tracestate"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ts).To(HaveLen(32))
+		Expect(ts[0].Key()).To(Equal("lightstep"))
+		Expect(ts[31].Key()).To(Equal("vendor30"))
+	})
+
+	It("makes callbacks in parallel", func() {
+		h.delay = 200 * time.Millisecond
+
+		start := time.Now()
+		resp := post(map[string]string{"traceparent": traceParent}, callbacks(5))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(time.Since(start)).To(BeNumerically("<", 800*time.Millisecond))
+		Expect(h.Requests()).To(HaveLen(5))
+	})
+
+	It("reports invalid requests and failed callbacks with the appropriate status", func() {
+		resp, err := http.Get(service.URL)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+
+		Expect(post(nil, "not json").StatusCode).To(Equal(http.StatusBadRequest))
+
+		h.status = http.StatusInternalServerError
+		Expect(post(nil, callbacks(1)).StatusCode).To(Equal(http.StatusBadGateway))
+	})
+
+	It("passes the conformance suite", func() {
+		report := conformance.Run(service.URL)
+		Expect(report.Passed()).To(BeTrue(), report.String())
+	})
+
+	It("times out slow callbacks", func() {
+		service.Close()
+		service = httptest.NewServer(newService(nil, "lightstep", 50*time.Millisecond))
+		h.delay = 500 * time.Millisecond
+
+		Expect(post(nil, callbacks(1)).StatusCode).To(Equal(http.StatusBadGateway))
+	})
+})
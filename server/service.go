@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	tracecontext "github.com/lightstep/tracecontext.go"
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+)
+
+const (
+	maxRequestBytes = 1 << 20
+	maxMembers      = 32
+)
+
+type testRequest struct {
+	URL       string        `json:"url"`
+	Arguments []interface{} `json:"arguments"`
+}
+
+// service implements the W3C trace-context test harness protocol: for each `testRequest` in the body of a request,
+// it POSTs the arguments to the URL, propagating the request's trace context to a new child span.
+type service struct {
+	client *http.Client
+	// vendor is the tracestate key under which the service records its own span ID.
+	vendor string
+	// timeout limits how long each callback may take.
+	timeout time.Duration
+}
+
+func newService(client *http.Client, vendor string, timeout time.Duration) *service {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &service{
+		client:  client,
+		vendor:  vendor,
+		timeout: timeout,
+	}
+}
+
+func (s *service) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body []testRequest
+	if err := json.NewDecoder(io.LimitReader(req.Body, maxRequestBytes)).Decode(&body); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	parent, err := s.extract(req.Header)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("generating trace context: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	errs := make([]error, len(body))
+	var wg sync.WaitGroup
+	for i, item := range body {
+		wg.Add(1)
+		go func(i int, item testRequest) {
+			defer wg.Done()
+			errs[i] = s.callback(req.Context(), parent, item)
+		}(i, item)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// extract returns the trace context of the request, or a new one if the `traceparent` header is invalid.
+// As the spec requires, the `tracestate` is discarded when the trace is restarted.
+func (s *service) extract(headers http.Header) (tracecontext.TraceContext, error) {
+	tc, err := tracecontext.FromHeaders(headers)
+	if err == nil {
+		return tc, nil
+	}
+
+	tc = tracecontext.TraceContext{}
+	tc.TraceParent.Version = traceparent.Version
+	tc.TraceParent.Flags.Random = true
+	for !tc.TraceParent.TraceID.IsValid() {
+		if _, err := rand.Read(tc.TraceParent.TraceID[:]); err != nil {
+			return tc, err
+		}
+	}
+	return tc, nil
+}
+
+// child returns the trace context for a new span that is a child of the parent,
+// with the service's own tracestate member moved to the front.
+func (s *service) child(parent tracecontext.TraceContext) (tracecontext.TraceContext, error) {
+	tc := parent
+	tc.TraceParent.Version = traceparent.Version
+	tc.TraceParent.SpanID = traceparent.SpanID{}
+	for !tc.TraceParent.SpanID.IsValid() {
+		if _, err := rand.Read(tc.TraceParent.SpanID[:]); err != nil {
+			return tc, err
+		}
+	}
+
+	if s.vendor == "" {
+		return tc, nil
+	}
+
+	ts := parent.TraceState.DeleteKey(s.vendor)
+	if len(ts) >= maxMembers {
+		ts = ts[:maxMembers-1]
+	}
+
+	tenantID, systemID, err := tracestate.ParseKey(s.vendor)
+	if err != nil {
+		return tc, err
+	}
+	tc.TraceState, err = ts.Set(tracestate.Member{
+		TenantID: tenantID,
+		SystemID: systemID,
+		Value:    tc.TraceParent.SpanID.String(),
+	})
+	return tc, err
+}
+
+func (s *service) callback(ctx context.Context, parent tracecontext.TraceContext, item testRequest) error {
+	tc, err := s.child(parent)
+	if err != nil {
+		return fmt.Errorf("generating trace context: %s", err)
+	}
+
+	b, err := json.Marshal(item.Arguments)
+	if err != nil {
+		return fmt.Errorf("encoding arguments for %s: %s", item.URL, err)
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	r, err := http.NewRequest(http.MethodPost, item.URL, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("creating request for %s: %s", item.URL, err)
+	}
+	r = r.WithContext(ctx)
+	r.Header.Set("Content-Type", "application/json")
+	tc.SetHeaders(r.Header)
+	if len(tc.TraceState) == 0 {
+		// Send no header, rather than an empty one, if there are no list members.
+		r.Header.Del("tracestate")
+	}
+
+	resp, err := s.client.Do(r)
+	if err != nil {
+		return fmt.Errorf("calling %s: %s", item.URL, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("calling %s: unexpected status %s", item.URL, resp.Status)
+	}
+	return nil
+}
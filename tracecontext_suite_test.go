@@ -0,0 +1,387 @@
+package tracecontext_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/lightstep/tracecontext.go"
+	"github.com/lightstep/tracecontext.go/traceparent"
+	"github.com/lightstep/tracecontext.go/tracestate"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	validTraceParent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	validTraceState  = "vendor=value,other@tenant=x"
+)
+
+func TestTracecontext(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tracecontext Suite")
+}
+
+func mustTraceContext(traceParent, traceState string) TraceContext {
+	tp, err := traceparent.ParseString(traceParent)
+	Expect(err).NotTo(HaveOccurred())
+	ts, err := tracestate.ParseString(traceState)
+	Expect(err).NotTo(HaveOccurred())
+	return TraceContext{TraceParent: tp, TraceState: ts}
+}
+
+var _ = Describe(".FromHeaders", func() {
+	const otherTraceParent = "00-0af7651916cd43dd8448eb211c80319c-00f067aa0ba902b7-00"
+
+	It("parses the traceparent and tracestate headers", func() {
+		headers := http.Header{}
+		headers.Set("traceparent", validTraceParent)
+		headers.Add("tracestate", "vendor=value")
+		headers.Add("tracestate", "other@tenant=x")
+
+		tc, err := FromHeaders(headers)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc).To(Equal(mustTraceContext(validTraceParent, validTraceState)))
+	})
+
+	It("ignores an invalid tracestate", func() {
+		headers := http.Header{}
+		headers.Set("traceparent", validTraceParent)
+		headers.Set("tracestate", "a=1,a=2")
+
+		tc, err := FromHeaders(headers)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceState).To(BeEmpty())
+	})
+
+	It("rejects multiple traceparent headers", func() {
+		headers := http.Header{}
+		headers.Add("traceparent", validTraceParent)
+		headers.Add("traceparent", validTraceParent)
+
+		_, err := FromHeaders(headers)
+		Expect(err).To(MatchError("tracecontext: Multiple traceparent headers"))
+	})
+
+	It("rejects comma-joined traceparent values", func() {
+		headers := http.Header{}
+		headers.Set("traceparent", validTraceParent+", "+validTraceParent)
+
+		_, err := FromHeaders(headers)
+		Expect(err).To(MatchError("tracecontext: Multiple comma-joined values in traceparent header"))
+	})
+
+	Describe("with options", func() {
+		fromHeaders := func(opts Options, lines ...string) (TraceContext, error) {
+			headers := http.Header{}
+			for _, line := range lines {
+				headers.Add("traceparent", line)
+			}
+			return FromHeadersWithOptions(headers, opts)
+		}
+
+		It("takes the first of multiple values", func() {
+			opts := Options{MultipleHeaders: TakeFirst, CombinedValues: TakeFirst}
+
+			tc, err := fromHeaders(opts, validTraceParent, otherTraceParent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tc.TraceParent.String()).To(Equal(validTraceParent))
+
+			tc, err = fromHeaders(opts, otherTraceParent+","+validTraceParent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tc.TraceParent.String()).To(Equal(otherTraceParent))
+		})
+
+		It("takes multiple values only if they are identical", func() {
+			opts := Options{MultipleHeaders: TakeIfIdentical, CombinedValues: TakeIfIdentical}
+
+			tc, err := fromHeaders(opts, validTraceParent, validTraceParent+" ,\t"+validTraceParent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tc.TraceParent.String()).To(Equal(validTraceParent))
+
+			_, err = fromHeaders(opts, validTraceParent, otherTraceParent)
+			Expect(err).To(MatchError("tracecontext: Conflicting traceparent values"))
+
+			_, err = fromHeaders(opts, validTraceParent+","+otherTraceParent)
+			Expect(err).To(MatchError("tracecontext: Conflicting traceparent values"))
+		})
+
+		It("applies separate policies to each shape", func() {
+			_, err := fromHeaders(Options{MultipleHeaders: TakeFirst}, validTraceParent+","+validTraceParent)
+			Expect(err).To(MatchError("tracecontext: Multiple comma-joined values in traceparent header"))
+
+			_, err = fromHeaders(Options{CombinedValues: TakeFirst}, validTraceParent, validTraceParent)
+			Expect(err).To(MatchError("tracecontext: Multiple traceparent headers"))
+
+			tc, err := fromHeaders(Options{MultipleHeaders: TakeIfIdentical, CombinedValues: TakeFirst}, validTraceParent+","+otherTraceParent, validTraceParent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tc.TraceParent.String()).To(Equal(validTraceParent))
+		})
+
+		It("normalizes the traceparent if a normalizer is set", func() {
+			var nz traceparent.Normalizer
+
+			_, err := fromHeaders(Options{}, strings.ToUpper(validTraceParent))
+			Expect(err).To(MatchError("tracecontext: Invalid traceparent format"))
+
+			tc, err := fromHeaders(Options{Normalizer: &nz}, strings.ToUpper(validTraceParent)+" ")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tc.TraceParent.String()).To(Equal(validTraceParent))
+			Expect(nz.Counts().Total).To(Equal(uint64(1)))
+		})
+
+		It("ignores whitespace and empty comma-joined values only if they are not rejected", func() {
+			_, err := fromHeaders(Options{}, validTraceParent+",")
+			Expect(err).To(MatchError("tracecontext: Multiple comma-joined values in traceparent header"))
+
+			_, err = fromHeaders(Options{}, " "+validTraceParent)
+			Expect(err).To(MatchError("tracecontext: Invalid traceparent format"))
+
+			for _, line := range []string{validTraceParent + ",", validTraceParent + ", ", " " + validTraceParent + ","} {
+				tc, err := fromHeaders(Options{CombinedValues: TakeFirst}, line)
+				Expect(err).NotTo(HaveOccurred(), line)
+				Expect(tc.TraceParent.String()).To(Equal(validTraceParent))
+			}
+
+			_, err = fromHeaders(Options{CombinedValues: TakeFirst}, " "+validTraceParent)
+			Expect(err).To(MatchError("tracecontext: Invalid traceparent format"))
+
+			nz := &traceparent.Normalizer{}
+			tc, err := fromHeaders(Options{CombinedValues: TakeFirst, Normalizer: nz}, " "+validTraceParent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tc.TraceParent.String()).To(Equal(validTraceParent))
+			Expect(nz.Counts().Whitespace).To(Equal(uint64(1)))
+		})
+	})
+})
+
+var _ = Describe(".FromHeadersWithOptions with a fallback", func() {
+	fallback := func(headers http.Header) (traceparent.TraceParent, error) {
+		return traceparent.ParseString(headers.Get("x-legacy-traceparent"))
+	}
+
+	It("uses the fallback only if there is no traceparent header", func() {
+		headers := http.Header{}
+		headers.Set("x-legacy-traceparent", validTraceParent)
+		headers.Set("tracestate", validTraceState)
+
+		tc, err := FromHeadersWithOptions(headers, Options{Fallback: fallback})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc).To(Equal(mustTraceContext(validTraceParent, validTraceState)))
+
+		lazy, err := LazyFromHeadersWithOptions(headers, Options{Fallback: fallback})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lazy.TraceParent.String()).To(Equal(validTraceParent))
+
+		_, err = FromHeaders(headers)
+		Expect(err).To(Equal(traceparent.ErrInvalidFormat))
+
+		headers.Set("traceparent", "invalid")
+		_, err = FromHeadersWithOptions(headers, Options{Fallback: fallback})
+		Expect(err).To(Equal(traceparent.ErrInvalidFormat))
+	})
+})
+
+var _ = Describe("TraceContext encoding", func() {
+	tc := mustTraceContext(validTraceParent, validTraceState)
+
+	It("round-trips through text", func() {
+		text, err := tc.MarshalText()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(text)).To(Equal(validTraceParent + " " + validTraceState))
+
+		var decoded TraceContext
+		Expect(decoded.UnmarshalText(text)).To(Succeed())
+		Expect(decoded).To(Equal(tc))
+
+		text, err = TraceContext{TraceParent: tc.TraceParent}.MarshalText()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(text)).To(Equal(validTraceParent))
+	})
+
+	It("round-trips through JSON", func() {
+		j, err := json.Marshal(tc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(j).To(MatchJSON(`{"traceparent":"` + validTraceParent + `","tracestate":"` + validTraceState + `"}`))
+
+		var decoded TraceContext
+		Expect(json.Unmarshal(j, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(tc))
+	})
+
+	It("accepts the object form of the traceparent in JSON", func() {
+		var decoded TraceContext
+		Expect(json.Unmarshal([]byte(`{"traceparent":{"version":"00","trace_id":"0af7651916cd43dd8448eb211c80319c","span_id":"b7ad6b7169203331","flags":"01"}}`), &decoded)).To(Succeed())
+		Expect(decoded.TraceParent).To(Equal(tc.TraceParent))
+		Expect(decoded.TraceState).To(BeEmpty())
+	})
+
+	It("round-trips through binary", func() {
+		b, err := tc.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b).To(HaveLen(traceparent.BinaryLen + len(validTraceState)))
+
+		var decoded TraceContext
+		Expect(decoded.UnmarshalBinary(b)).To(Succeed())
+		Expect(decoded).To(Equal(tc))
+	})
+
+	It("errors for an invalid traceparent but ignores an invalid tracestate", func() {
+		var decoded TraceContext
+		Expect(decoded.UnmarshalText([]byte("00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01"))).To(MatchError("tracecontext: Invalid traceparent span ID"))
+		Expect(json.Unmarshal([]byte(`{"tracestate":"a=1"}`), &decoded)).To(MatchError("tracecontext: Invalid traceparent format"))
+		Expect(decoded.UnmarshalBinary([]byte{0})).To(MatchError("tracecontext: Invalid traceparent format"))
+
+		Expect(decoded.UnmarshalText([]byte(validTraceParent + " a=1,a=2"))).To(Succeed())
+		Expect(decoded.TraceParent).To(Equal(tc.TraceParent))
+		Expect(decoded.TraceState).To(BeEmpty())
+	})
+})
+
+var _ = Describe(".LazyFromHeaders", func() {
+	It("propagates the tracestate headers unchanged unless modified", func() {
+		headers := http.Header{}
+		headers.Set("traceparent", validTraceParent)
+		headers.Add("tracestate", "vendor=value ")
+		headers.Add("tracestate", "other@tenant=x")
+
+		tc, err := LazyFromHeaders(headers)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceParent.String()).To(Equal(validTraceParent))
+		Expect(tc.TraceState.Parsed()).To(BeFalse())
+
+		out := http.Header{}
+		tc.SetHeaders(out)
+		Expect(out.Get("traceparent")).To(Equal(validTraceParent))
+		Expect(out.Get("tracestate")).To(Equal("vendor=value ,other@tenant=x"))
+
+		Expect(tc.TraceContext()).To(Equal(mustTraceContext(validTraceParent, validTraceState)))
+
+		tc.TraceState.Delete("other@tenant")
+		tc.TraceState.Delete("vendor")
+		tc.SetHeaders(out)
+		Expect(out).NotTo(HaveKey("Tracestate"))
+	})
+
+	It("defers tracestate validation to the caller", func() {
+		headers := http.Header{}
+		headers.Set("traceparent", validTraceParent)
+		headers.Set("tracestate", "a=1,a=2")
+
+		tc, err := LazyFromHeaders(headers)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceState.Validate()).To(MatchError("tracecontext: Duplicate list member key in tracestate"))
+
+		eager, err := FromHeaders(headers)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceContext()).To(Equal(eager))
+
+		out := http.Header{}
+		tc.SetHeaders(out)
+		Expect(out.Get("tracestate")).To(Equal("a=1,a=2"))
+
+		if tc.TraceState.Validate() != nil {
+			tc.TraceState = tracestate.Lazy{}
+		}
+		tc.SetHeaders(out)
+		Expect(out).NotTo(HaveKey("Tracestate"))
+	})
+
+	It("errors under the same conditions as FromHeadersWithOptions", func() {
+		headers := http.Header{}
+		headers.Add("traceparent", validTraceParent)
+		headers.Add("traceparent", validTraceParent)
+
+		_, err := LazyFromHeaders(headers)
+		Expect(err).To(Equal(ErrInvalidHeadersMultipleTraceParent))
+
+		tc, err := LazyFromHeadersWithOptions(headers, Options{MultipleHeaders: TakeIfIdentical})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tc.TraceParent.String()).To(Equal(validTraceParent))
+	})
+})
+
+var _ = Describe("Link", func() {
+	It("links to the caller when extraction fails", func() {
+		headers := http.Header{}
+		headers.Add("traceparent", " "+strings.ToUpper(validTraceParent))
+		headers.Add("traceparent", "00-00000000000000000000000000000000-b7ad6b7169203331-01")
+		headers.Set("tracestate", validTraceState)
+
+		_, err := FromHeaders(headers)
+		Expect(err).To(HaveOccurred())
+
+		link, ok := LinkFromHeaders(headers, map[string]string{"reason": "restarted"})
+		Expect(ok).To(BeTrue())
+		Expect(link).To(Equal(mustTraceContext(validTraceParent, validTraceState).Link(map[string]string{"reason": "restarted"})))
+
+		headers.Del("traceparent")
+		_, ok = LinkFromHeaders(headers, nil)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("links to each distinct sender in a batch", func() {
+		const otherTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"
+		message := func(traceParent string) http.Header {
+			return http.Header{"Traceparent": {traceParent}}
+		}
+
+		links := LinksFromBatch([]http.Header{
+			message(validTraceParent),
+			message("invalid"),
+			message(otherTraceParent),
+			message(validTraceParent),
+			{},
+		})
+		Expect(links).To(HaveLen(2))
+		Expect(links[0].TraceParent.String()).To(Equal(validTraceParent))
+		Expect(links[1].TraceParent.String()).To(Equal(otherTraceParent))
+	})
+
+	It("encodes as many links as fit in a tracestate value", func() {
+		var links []Link
+		for i := 0; i < 6; i++ {
+			tc := mustTraceContext(validTraceParent, validTraceState)
+			tc.TraceParent.SpanID[7] = byte(i + 1)
+			links = append(links, tc.Link(map[string]string{"ignored": "true"}))
+		}
+
+		value, n := LinksValue(links)
+		Expect(n).To(Equal(4))
+		Expect(len(value)).To(BeNumerically("<=", 256))
+		Expect(value).To(HavePrefix("0af7651916cd43dd8448eb211c80319c-b7ad6b7169203301-01;"))
+
+		member, err := tracestate.NewSimpleMember("links", value)
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded, err := ParseLinksValue(member.Value)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decoded).To(HaveLen(4))
+		for i, link := range decoded {
+			Expect(link.TraceParent).To(Equal(links[i].TraceParent))
+		}
+
+		_, err = ParseLinksValue("0af7651916cd43dd8448eb211c80319c-b7ad6b7169203301")
+		Expect(err).To(Equal(ErrInvalidLinks))
+		decoded, err = ParseLinksValue("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decoded).To(BeEmpty())
+	})
+
+	It("encodes in logfmt and JSON", func() {
+		link := mustTraceContext(validTraceParent, validTraceState).Link(map[string]string{"reason": "untrusted caller", "batch": "7"})
+		Expect(link.String()).To(Equal(`traceparent=` + validTraceParent + ` tracestate="vendor=value,other@tenant=x" batch=7 reason="untrusted caller"`))
+
+		j, err := json.Marshal(link)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(j)).To(Equal(`{"traceparent":"` + validTraceParent + `","tracestate":"vendor=value,other@tenant=x","attributes":{"batch":"7","reason":"untrusted caller"}}`))
+
+		var decoded Link
+		Expect(json.Unmarshal(j, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(link))
+
+		Expect(json.Unmarshal([]byte(`{"tracestate":"a=1"}`), &decoded)).To(Equal(traceparent.ErrInvalidFormat))
+		Expect(mustTraceContext(validTraceParent, "").Link(nil).String()).To(Equal("traceparent=" + validTraceParent))
+	})
+})
@@ -0,0 +1,121 @@
+package traceparent
+
+const (
+	// BinaryLen is the length of a `TraceParent` encoded in the W3C binary format by `MarshalBinary`.
+	BinaryLen = numVersionBytes + 1 + numTraceIDBytes + 1 + numSpanIDBytes + 1 + numFlagBytes
+)
+
+const (
+	traceIDFieldID = 0
+	spanIDFieldID  = 1
+	flagsFieldID   = 2
+)
+
+// MarshalBinary implements `encoding.BinaryMarshaler`, encoding the `TraceParent` in the W3C binary format,
+// as used by, e.g., gRPC's `grpc-trace-bin` header: the version, followed by the trace ID, span ID and flags,
+// each prefixed with its field ID. The result is always `BinaryLen` bytes.
+func (tp TraceParent) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, BinaryLen)
+	b = append(b, tp.Version)
+	b = append(b, traceIDFieldID)
+	b = append(b, tp.TraceID[:]...)
+	b = append(b, spanIDFieldID)
+	b = append(b, tp.SpanID[:]...)
+	b = append(b, flagsFieldID, tp.Flags.encode())
+	return b, nil
+}
+
+// UnmarshalBinary implements `encoding.BinaryUnmarshaler`, decoding the W3C binary format as `ParseBinary` does.
+// The `TraceParent` is left unchanged if there is an error.
+func (tp *TraceParent) UnmarshalBinary(data []byte) error {
+	parsed, err := ParseBinary(data)
+	if err != nil {
+		return err
+	}
+	*tp = parsed
+	return nil
+}
+
+// ParseBinary attempts to decode a `TraceParent` from the W3C binary format.
+// The trace ID and span ID fields are required, and the flags field is optional.
+// Field IDs are ordered so that parsing can stop at the first unknown field ID, whose length cannot be known,
+// and ignore the rest, as later versions may append fields.
+// It returns the same errors as `Parse` for invalid versions, trace IDs and span IDs.
+func ParseBinary(b []byte) (tp TraceParent, err error) {
+	if len(b) < numVersionBytes {
+		return tp, ErrInvalidFormat
+	}
+
+	if b[0] > maxVersion {
+		return tp, ErrInvalidVersion
+	}
+	b = b[numVersionBytes:]
+
+	var traceID, spanID []byte
+	var flags []byte
+	nextFieldID := traceIDFieldID
+
+	for len(b) > 0 {
+		fieldID := int(b[0])
+		if fieldID > flagsFieldID {
+			break
+		}
+		if fieldID < nextFieldID {
+			return tp, ErrInvalidFormat
+		}
+
+		var n int
+		switch fieldID {
+		case traceIDFieldID:
+			n = numTraceIDBytes
+		case spanIDFieldID:
+			n = numSpanIDBytes
+		case flagsFieldID:
+			n = numFlagBytes
+		}
+		if len(b) < 1+n {
+			return tp, ErrInvalidFormat
+		}
+
+		field := b[1 : 1+n]
+		switch fieldID {
+		case traceIDFieldID:
+			traceID = field
+		case spanIDFieldID:
+			spanID = field
+		case flagsFieldID:
+			flags = field
+		}
+
+		b = b[1+n:]
+		nextFieldID = fieldID + 1
+	}
+
+	if traceID == nil || spanID == nil {
+		return tp, ErrInvalidFormat
+	}
+	if isZero(traceID) {
+		return tp, ErrInvalidTraceID
+	}
+	if isZero(spanID) {
+		return tp, ErrInvalidSpanID
+	}
+
+	tp.Version = Version
+	copy(tp.TraceID[:], traceID)
+	copy(tp.SpanID[:], spanID)
+	if flags != nil {
+		tp.Flags = decodeFlags(flags[0])
+	}
+
+	return tp, nil
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package traceparent_test
+
+import (
+	"encoding/hex"
+	"testing/quick"
+
+	. "github.com/lightstep/tracecontext.go/traceparent"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("W3C binary format", func() {
+	const (
+		encoded       = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+		encodedBinary = "00000af7651916cd43dd8448eb211c80319c01b7ad6b716920333102" + "01"
+	)
+
+	decodeHex := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		Expect(err).NotTo(HaveOccurred())
+		return b
+	}
+
+	It("encodes each field prefixed with its field ID", func() {
+		tp, err := ParseString(encoded)
+		Expect(err).NotTo(HaveOccurred())
+
+		b, err := tp.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(b).To(HaveLen(BinaryLen))
+		Expect(hex.EncodeToString(b)).To(Equal(encodedBinary))
+	})
+
+	It("round-trips against the text form", func() {
+		quick.Check(func(traceID [16]byte, spanID [8]byte, flags [1]byte) bool {
+			// Discard example if it's invalid
+			if traceID == invalidTraceIDAllZeroes || spanID == invalidSpanIDAllZeroes {
+				return true
+			}
+
+			fromText, err := ParseString(encodeTraceParent(version0[:], traceID[:], spanID[:], flags[:]))
+			Expect(err).NotTo(HaveOccurred())
+
+			b, err := fromText.MarshalBinary()
+			Expect(err).NotTo(HaveOccurred())
+			fromBinary, err := ParseBinary(b)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fromBinary).To(Equal(fromText))
+			Expect(fromBinary.String()).To(Equal(fromText.String()))
+
+			return true
+		}, nil)
+	})
+
+	It("defaults the flags if they are omitted", func() {
+		tp, err := ParseBinary(decodeHex(encodedBinary[:len(encodedBinary)-4]))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tp.Flags).To(Equal(Flags{}))
+	})
+
+	It("stops at the first unknown field ID", func() {
+		quick.Check(func(version [1]byte, extra []byte) bool {
+			// Discard example if it's invalid
+			if version == invalidVersionAboveMax {
+				return true
+			}
+
+			b := decodeHex(encodedBinary)
+			b[0] = version[0]
+			b = append(b, 3)
+			b = append(b, extra...)
+
+			tp, err := ParseBinary(b)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tp.String()).To(Equal(encoded))
+
+			return true
+		}, nil)
+
+		tp, err := ParseBinary(decodeHex(encodedBinary + "03"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tp.String()).To(Equal(encoded))
+
+		tp, err = ParseBinary(decodeHex(encodedBinary[:len(encodedBinary)-4] + "03ffff"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tp.String()).To(Equal(encoded[:len(encoded)-2] + "00"))
+	})
+
+	It("errors if the encoding is invalid", func() {
+		for _, s := range []string{
+			"",
+			"00",
+			encodedBinary[:len(encodedBinary)-2],
+			encodedBinary + "00",
+			"0001b7ad6b7169203331000af7651916cd43dd8448eb211c80319c",
+			"00000af7651916cd43dd8448eb211c80319c",
+			"0001b7ad6b7169203331",
+		} {
+			_, err := ParseBinary(decodeHex(s))
+			Expect(err).To(MatchError("tracecontext: Invalid traceparent format"), s)
+		}
+	})
+
+	It("errors if a field is invalid", func() {
+		_, err := ParseBinary(decodeHex("ff" + encodedBinary[2:]))
+		Expect(err).To(MatchError("tracecontext: Invalid traceparent version"))
+
+		_, err = ParseBinary(decodeHex("000000000000000000000000000000000000" + encodedBinary[36:]))
+		Expect(err).To(MatchError("tracecontext: Invalid traceparent trace ID"))
+
+		_, err = ParseBinary(decodeHex(encodedBinary[:38] + "0000000000000000" + encodedBinary[54:]))
+		Expect(err).To(MatchError("tracecontext: Invalid traceparent span ID"))
+	})
+})
@@ -0,0 +1,122 @@
+package traceparent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Object represents a `TraceParent` as a JSON object, with each field hex-encoded separately.
+type Object struct {
+	Version string `json:"version"`
+	TraceID string `json:"trace_id"`
+	SpanID  string `json:"span_id"`
+	Flags   string `json:"flags"`
+}
+
+// TraceParent attempts to decode a `TraceParent` from the `Object`, validating each field separately.
+// It returns `ErrInvalidFormat` if any field is not lowercase hex of the expected length,
+// and otherwise returns an error under the same conditions as `Parse`.
+func (o Object) TraceParent() (tp TraceParent, err error) {
+	fields := []struct {
+		value string
+		n     int
+	}{
+		{o.Version, numVersionBytes},
+		{o.TraceID, numTraceIDBytes},
+		{o.SpanID, numSpanIDBytes},
+		{o.Flags, numFlagBytes},
+	}
+	for _, f := range fields {
+		if len(f.value) != 2*f.n || !lowerHexRe.MatchString(f.value) {
+			return tp, ErrInvalidFormat
+		}
+	}
+
+	if _, err = parseVersion([]byte(o.Version)); err != nil {
+		return
+	}
+
+	var traceID TraceID
+	if traceID, err = parseTraceID([]byte(o.TraceID)); err != nil {
+		return
+	}
+
+	var spanID SpanID
+	if spanID, err = parseSpanID([]byte(o.SpanID)); err != nil {
+		return
+	}
+
+	var flags Flags
+	if flags, err = parseFlags([]byte(o.Flags)); err != nil {
+		return
+	}
+
+	tp.Version = Version
+	tp.TraceID = traceID
+	tp.SpanID = spanID
+	tp.Flags = flags
+
+	return tp, nil
+}
+
+// Object returns the `TraceParent` in its JSON object form.
+func (tp TraceParent) Object() Object {
+	return Object{
+		Version: fmt.Sprintf("%02x", tp.Version),
+		TraceID: tp.TraceID.String(),
+		SpanID:  tp.SpanID.String(),
+		Flags:   tp.Flags.String(),
+	}
+}
+
+// MarshalText implements `encoding.TextMarshaler`, encoding the `TraceParent` as by `String`.
+func (tp TraceParent) MarshalText() ([]byte, error) {
+	return []byte(tp.String()), nil
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+// It returns an error under the same conditions as `Parse`.
+func (tp *TraceParent) UnmarshalText(text []byte) error {
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*tp = parsed
+	return nil
+}
+
+// MarshalJSON implements `json.Marshaler`, encoding the `TraceParent` as a JSON string.
+// Use `Object` to encode it as a JSON object instead.
+func (tp TraceParent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tp.String())
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`.
+// It accepts both a JSON string and the JSON object form produced by `Object`, and leaves the `TraceParent` unchanged for `null`.
+// It returns an error under the same conditions as `Parse`.
+func (tp *TraceParent) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if bytes.HasPrefix(data, []byte("{")) {
+		var o Object
+		if err := json.Unmarshal(data, &o); err != nil {
+			return err
+		}
+		parsed, err := o.TraceParent()
+		if err != nil {
+			return err
+		}
+		*tp = parsed
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return tp.UnmarshalText([]byte(s))
+}
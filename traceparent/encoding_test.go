@@ -0,0 +1,119 @@
+package traceparent_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing/quick"
+
+	. "github.com/lightstep/tracecontext.go/traceparent"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Encoding", func() {
+	const encoded = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
+	var tp TraceParent
+	BeforeEach(func() {
+		var err error
+		tp, err = ParseString(encoded)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("round-trips valid traceparents through every encoding", func() {
+		quick.Check(func(traceID [16]byte, spanID [8]byte, recorded, random bool) bool {
+			// Discard example if it's invalid
+			if traceID == invalidTraceIDAllZeroes || spanID == invalidSpanIDAllZeroes {
+				return true
+			}
+
+			original := TraceParent{TraceID: traceID, SpanID: spanID, Flags: Flags{Recorded: recorded, Random: random}}
+
+			text, err := original.MarshalText()
+			Expect(err).NotTo(HaveOccurred())
+			var fromText TraceParent
+			Expect(fromText.UnmarshalText(text)).To(Succeed())
+			Expect(fromText).To(Equal(original))
+
+			b, err := original.MarshalBinary()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(b).To(HaveLen(BinaryLen))
+			var fromBinary TraceParent
+			Expect(fromBinary.UnmarshalBinary(b)).To(Succeed())
+			Expect(fromBinary).To(Equal(original))
+
+			j, err := json.Marshal(original)
+			Expect(err).NotTo(HaveOccurred())
+			var fromJSON TraceParent
+			Expect(json.Unmarshal(j, &fromJSON)).To(Succeed())
+			Expect(fromJSON).To(Equal(original))
+
+			return true
+		}, nil)
+	})
+
+	It("encodes JSON as a string or an object", func() {
+		j, err := json.Marshal(tp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(j)).To(Equal(`"` + encoded + `"`))
+
+		j, err = json.Marshal(tp.Object())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(j).To(MatchJSON(`{"version":"00","trace_id":"0af7651916cd43dd8448eb211c80319c","span_id":"b7ad6b7169203331","flags":"01"}`))
+
+		var fromObject TraceParent
+		Expect(json.Unmarshal(j, &fromObject)).To(Succeed())
+		Expect(fromObject).To(Equal(tp))
+	})
+
+	It("applies the same validation as parsing", func() {
+		var decoded TraceParent
+		Expect(decoded.UnmarshalText([]byte("00-00000000000000000000000000000000-b7ad6b7169203331-01"))).To(MatchError("tracecontext: Invalid traceparent trace ID"))
+		Expect(json.Unmarshal([]byte(`"ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"`), &decoded)).To(MatchError("tracecontext: Invalid traceparent version"))
+		Expect(json.Unmarshal([]byte(`{"version":"00","trace_id":"0af7651916cd43dd8448eb211c80319c","span_id":"0000000000000000","flags":"01"}`), &decoded)).To(MatchError("tracecontext: Invalid traceparent span ID"))
+		Expect(decoded.UnmarshalBinary(make([]byte, BinaryLen))).To(MatchError("tracecontext: Invalid traceparent format"))
+		Expect(decoded.UnmarshalBinary(zeroTraceIDBinary(tp))).To(MatchError("tracecontext: Invalid traceparent trace ID"))
+		Expect(decoded).To(Equal(TraceParent{}))
+	})
+
+	It("validates each field of the object form separately", func() {
+		valid := tp.Object()
+
+		o := valid
+		o.Version = "01"
+		decoded, err := o.TraceParent()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decoded).To(Equal(tp))
+
+		for _, o := range []Object{
+			{Version: "01", TraceID: valid.TraceID, SpanID: valid.SpanID, Flags: "01-xyz"},
+			{Version: "01", TraceID: valid.TraceID + "-" + valid.SpanID, SpanID: "01", Flags: ""},
+			{Version: "00-" + valid.TraceID, TraceID: valid.SpanID, SpanID: valid.Flags, Flags: "00"},
+			{Version: "00", TraceID: strings.ToUpper(valid.TraceID), SpanID: valid.SpanID, Flags: valid.Flags},
+			{Version: "0", TraceID: valid.TraceID, SpanID: valid.SpanID, Flags: valid.Flags},
+			{Version: "00", TraceID: valid.TraceID, SpanID: valid.SpanID + "00", Flags: valid.Flags},
+			{Version: "00", TraceID: valid.TraceID, SpanID: valid.SpanID, Flags: "0x"},
+			{},
+		} {
+			_, err := o.TraceParent()
+			Expect(err).To(MatchError("tracecontext: Invalid traceparent format"), fmt.Sprintf("%+v", o))
+		}
+
+		o = valid
+		o.Version = "ff"
+		_, err = o.TraceParent()
+		Expect(err).To(MatchError("tracecontext: Invalid traceparent version"))
+
+		o = valid
+		o.TraceID = strings.Repeat("0", 32)
+		_, err = o.TraceParent()
+		Expect(err).To(MatchError("tracecontext: Invalid traceparent trace ID"))
+	})
+
+	It("leaves the traceparent unchanged for JSON null", func() {
+		decoded := tp
+		Expect(json.Unmarshal([]byte("null"), &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(tp))
+	})
+})
@@ -0,0 +1,159 @@
+package traceparent
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+var (
+	traceIDRe = regexp.MustCompile(`^[a-f0-9]{32}$`)
+	spanIDRe  = regexp.MustCompile(`^[a-f0-9]{16}$`)
+)
+
+// TraceID identifies a trace. A `TraceID` that contains only 0 bytes is invalid.
+type TraceID [16]byte
+
+// ParseTraceID attempts to decode a `TraceID` from 32 lowercase hex characters.
+// It returns `ErrInvalidFormat` if the string is incorrectly formatted, or `ErrInvalidTraceID` if it contains only 0 bytes.
+func ParseTraceID(s string) (TraceID, error) {
+	if !traceIDRe.MatchString(s) {
+		return TraceID{}, ErrInvalidFormat
+	}
+	return parseTraceID([]byte(s))
+}
+
+// TraceIDFromUint64s returns the `TraceID` whose high and low 8 bytes are the big-endian encodings of the given values.
+// A 64-bit trace ID from another system is converted by passing 0 as the high half.
+func TraceIDFromUint64s(high, low uint64) TraceID {
+	var id TraceID
+	binary.BigEndian.PutUint64(id[:8], high)
+	binary.BigEndian.PutUint64(id[8:], low)
+	return id
+}
+
+// Uint64s returns the high and low 8 bytes of the `TraceID` as big-endian integers.
+func (id TraceID) Uint64s() (high, low uint64) {
+	return binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])
+}
+
+// IsValid reports whether the `TraceID` contains at least one non-zero byte.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// String encodes the `TraceID` as 32 lowercase hex characters.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// Format implements `fmt.Formatter`. The `%x` and `%X` verbs encode the raw bytes, as they did when the ID was a plain byte array,
+// so `%032x` remains 32 hex characters. Other verbs format the result of `String`.
+func (id TraceID) Format(f fmt.State, verb rune) {
+	formatID(f, verb, [16]byte(id), id.String())
+}
+
+// MarshalText implements `encoding.TextMarshaler`, encoding the `TraceID` as by `String`.
+func (id TraceID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+// Unlike `ParseTraceID`, it accepts the all-zero ID that `MarshalText` encodes for an unset `TraceID`, so that it round-trips;
+// use `IsValid` to reject it. It returns an error if the text is incorrectly formatted.
+func (id *TraceID) UnmarshalText(text []byte) error {
+	parsed, err := ParseTraceID(string(text))
+	if err == ErrInvalidTraceID {
+		parsed, err = TraceID{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// SpanID identifies a span within a trace. A `SpanID` that contains only 0 bytes is invalid.
+type SpanID [8]byte
+
+// ParseSpanID attempts to decode a `SpanID` from 16 lowercase hex characters.
+// It returns `ErrInvalidFormat` if the string is incorrectly formatted, or `ErrInvalidSpanID` if it contains only 0 bytes.
+func ParseSpanID(s string) (SpanID, error) {
+	if !spanIDRe.MatchString(s) {
+		return SpanID{}, ErrInvalidFormat
+	}
+	return parseSpanID([]byte(s))
+}
+
+// SpanIDFromUint64 returns the `SpanID` that is the big-endian encoding of the given value.
+func SpanIDFromUint64(v uint64) SpanID {
+	var id SpanID
+	binary.BigEndian.PutUint64(id[:], v)
+	return id
+}
+
+// Uint64 returns the `SpanID` as a big-endian integer.
+func (id SpanID) Uint64() uint64 {
+	return binary.BigEndian.Uint64(id[:])
+}
+
+// IsValid reports whether the `SpanID` contains at least one non-zero byte.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// String encodes the `SpanID` as 16 lowercase hex characters.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// Format implements `fmt.Formatter`. The `%x` and `%X` verbs encode the raw bytes, as they did when the ID was a plain byte array,
+// so `%016x` remains 16 hex characters. Other verbs format the result of `String`.
+func (id SpanID) Format(f fmt.State, verb rune) {
+	formatID(f, verb, [8]byte(id), id.String())
+}
+
+// MarshalText implements `encoding.TextMarshaler`, encoding the `SpanID` as by `String`.
+func (id SpanID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+// Unlike `ParseSpanID`, it accepts the all-zero ID that `MarshalText` encodes for an unset `SpanID`, so that it round-trips;
+// use `IsValid` to reject it. It returns an error if the text is incorrectly formatted.
+func (id *SpanID) UnmarshalText(text []byte) error {
+	parsed, err := ParseSpanID(string(text))
+	if err == ErrInvalidSpanID {
+		parsed, err = SpanID{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// formatID formats an ID's underlying byte array for `%x`, `%X` and `%#v`, and its string form otherwise,
+// passing through the flags, width and precision.
+func formatID(f fmt.State, verb rune, raw interface{}, s string) {
+	directive := "%"
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			directive += string(flag)
+		}
+	}
+	if width, ok := f.Width(); ok {
+		directive += fmt.Sprint(width)
+	}
+	if precision, ok := f.Precision(); ok {
+		directive += "." + fmt.Sprint(precision)
+	}
+	directive += string(verb)
+
+	if verb == 'x' || verb == 'X' || verb == 'v' && f.Flag('#') {
+		fmt.Fprintf(f, directive, raw)
+		return
+	}
+	fmt.Fprintf(f, directive, s)
+}
@@ -0,0 +1,154 @@
+package traceparent_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing/quick"
+
+	. "github.com/lightstep/tracecontext.go/traceparent"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TraceID", func() {
+	It("round-trips through its string form", func() {
+		quick.Check(func(b [16]byte) bool {
+			id := TraceID(b)
+			if !id.IsValid() {
+				return true
+			}
+
+			parsed, err := ParseTraceID(id.String())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(Equal(id))
+
+			return true
+		}, nil)
+	})
+
+	It("round-trips through 64-bit halves", func() {
+		quick.Check(func(high, low uint64) bool {
+			id := TraceIDFromUint64s(high, low)
+			h, l := id.Uint64s()
+			Expect(h).To(Equal(high))
+			Expect(l).To(Equal(low))
+			return true
+		}, nil)
+
+		Expect(TraceIDFromUint64s(0, 0xb7ad6b7169203331).String()).To(Equal("0000000000000000b7ad6b7169203331"))
+	})
+
+	It("is invalid if all bytes are 0", func() {
+		Expect(TraceID{}.IsValid()).To(BeFalse())
+		Expect(TraceID{15: 1}.IsValid()).To(BeTrue())
+
+		_, err := ParseTraceID("00000000000000000000000000000000")
+		Expect(err).To(MatchError("tracecontext: Invalid traceparent trace ID"))
+	})
+
+	It("errors if the string is incorrectly formatted", func() {
+		for _, s := range []string{"", "0AF7651916CD43DD8448EB211C80319C", "0af7651916cd43dd8448eb211c80319", "0af7651916cd43dd8448eb211c80319cc"} {
+			_, err := ParseTraceID(s)
+			Expect(err).To(MatchError("tracecontext: Invalid traceparent format"))
+		}
+	})
+
+	It("encodes as JSON text", func() {
+		id, err := ParseTraceID("0af7651916cd43dd8448eb211c80319c")
+		Expect(err).NotTo(HaveOccurred())
+
+		j, err := json.Marshal(id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(j)).To(Equal(`"0af7651916cd43dd8448eb211c80319c"`))
+
+		var decoded TraceID
+		Expect(json.Unmarshal(j, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(id))
+	})
+
+	It("round-trips unset IDs through JSON", func() {
+		type ids struct {
+			TraceID TraceID
+			SpanID  SpanID
+		}
+
+		j, err := json.Marshal(ids{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(j).To(MatchJSON(`{"TraceID":"00000000000000000000000000000000","SpanID":"0000000000000000"}`))
+
+		decoded := ids{TraceID: TraceIDFromUint64s(1, 2), SpanID: SpanIDFromUint64(3)}
+		Expect(json.Unmarshal(j, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(ids{}))
+		Expect(decoded.TraceID.IsValid()).To(BeFalse())
+		Expect(decoded.SpanID.IsValid()).To(BeFalse())
+
+		var tp TraceParent
+		Expect(json.Unmarshal([]byte(`"00-00000000000000000000000000000000-b7ad6b7169203331-01"`), &tp)).To(MatchError("tracecontext: Invalid traceparent trace ID"))
+	})
+})
+
+var _ = Describe("ID formatting", func() {
+	It("formats the raw bytes with %x and %X, as the byte arrays of earlier versions did", func() {
+		tp, err := ParseString("00-0af7651916cd43dd8448eb211c80319c-00f067aa0ba902b7-01")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fmt.Sprintf("%032x", tp.TraceID)).To(Equal("0af7651916cd43dd8448eb211c80319c"))
+		Expect(fmt.Sprintf("%016x", tp.SpanID)).To(Equal("00f067aa0ba902b7"))
+		Expect(fmt.Sprintf("%X", tp.SpanID)).To(Equal("00F067AA0BA902B7"))
+		Expect(fmt.Sprintf("%x-%x", tp.TraceID, tp.SpanID)).To(Equal("0af7651916cd43dd8448eb211c80319c-00f067aa0ba902b7"))
+
+		quick.Check(func(traceID [16]byte, spanID [8]byte) bool {
+			for _, format := range []string{"%x", "%X", "%032x", "% x", "%#x", "%#v"} {
+				Expect(fmt.Sprintf(format, TraceID(traceID))).To(Equal(fmt.Sprintf(format, traceID)))
+				Expect(fmt.Sprintf(format, SpanID(spanID))).To(Equal(fmt.Sprintf(format, spanID)))
+			}
+			return true
+		}, nil)
+	})
+
+	It("formats the string form with other verbs", func() {
+		tp, err := ParseString("00-0af7651916cd43dd8448eb211c80319c-00f067aa0ba902b7-01")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fmt.Sprintf("%s/%v", tp.TraceID, tp.SpanID)).To(Equal("0af7651916cd43dd8448eb211c80319c/00f067aa0ba902b7"))
+		Expect(fmt.Sprintf("%20s|%q", tp.SpanID, tp.SpanID)).To(Equal("    00f067aa0ba902b7|\"00f067aa0ba902b7\""))
+	})
+})
+
+var _ = Describe("SpanID", func() {
+	It("round-trips through its string form", func() {
+		quick.Check(func(b [8]byte) bool {
+			id := SpanID(b)
+			if !id.IsValid() {
+				return true
+			}
+
+			parsed, err := ParseSpanID(id.String())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(Equal(id))
+
+			return true
+		}, nil)
+	})
+
+	It("round-trips through a 64-bit integer", func() {
+		quick.Check(func(v uint64) bool {
+			Expect(SpanIDFromUint64(v).Uint64()).To(Equal(v))
+			return true
+		}, nil)
+
+		Expect(SpanIDFromUint64(0xb7ad6b7169203331).String()).To(Equal("b7ad6b7169203331"))
+	})
+
+	It("is invalid if all bytes are 0", func() {
+		Expect(SpanID{}.IsValid()).To(BeFalse())
+
+		_, err := ParseSpanID("0000000000000000")
+		Expect(err).To(MatchError("tracecontext: Invalid traceparent span ID"))
+	})
+
+	It("errors if the string is incorrectly formatted", func() {
+		var id SpanID
+		Expect(id.UnmarshalText([]byte("B7AD6B7169203331"))).To(MatchError("tracecontext: Invalid traceparent format"))
+	})
+})
@@ -0,0 +1,90 @@
+package traceparent
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// Normalization describes the changes made by `Normalize` to canonicalize a `traceparent` value.
+type Normalization uint8
+
+const (
+	// NormalizedCase indicates that uppercase characters were converted to lowercase.
+	NormalizedCase Normalization = 1 << iota
+	// NormalizedWhitespace indicates that leading or trailing whitespace was removed.
+	NormalizedWhitespace
+)
+
+const whitespace = " \t"
+
+// Normalize canonicalizes a `traceparent` value that the spec requires to be rejected, but that senders commonly produce:
+// it removes leading and trailing whitespace and converts uppercase characters to lowercase.
+// It returns the canonical value and the changes that were made, which are 0 if the value was already canonical.
+func Normalize(s string) (string, Normalization) {
+	var n Normalization
+
+	if trimmed := strings.Trim(s, whitespace); trimmed != s {
+		s = trimmed
+		n |= NormalizedWhitespace
+	}
+	if lower := strings.ToLower(s); lower != s {
+		s = lower
+		n |= NormalizedCase
+	}
+
+	return s, n
+}
+
+// NormalizationCounts holds the number of values a `Normalizer` accepted only after normalizing them.
+type NormalizationCounts struct {
+	// Case is the number of values that contained uppercase characters.
+	Case uint64
+	// Whitespace is the number of values that contained leading or trailing whitespace.
+	Whitespace uint64
+	// Total is the number of values that needed any normalization.
+	Total uint64
+}
+
+// Normalizer parses `traceparent` values leniently, normalizing them first, and counts how often normalization was necessary.
+// This is intended for migration periods, so that non-compliant senders can be found without dropping their traces.
+// The zero value is ready to use, and a `Normalizer` is safe for concurrent use.
+type Normalizer struct {
+	counts NormalizationCounts
+}
+
+// Parse attempts to decode a `TraceParent` from a byte array after normalizing it.
+// It returns an error if the normalized value is incorrectly formatted or otherwise invalid.
+func (nz *Normalizer) Parse(b []byte) (TraceParent, error) {
+	return nz.ParseString(string(b))
+}
+
+// ParseString attempts to decode a `TraceParent` from a string after normalizing it.
+// It returns an error if the normalized value is incorrectly formatted or otherwise invalid.
+// Values are only counted if they are valid after normalization.
+func (nz *Normalizer) ParseString(s string) (TraceParent, error) {
+	s, n := Normalize(s)
+
+	tp, err := ParseString(s)
+	if err != nil || n == 0 {
+		return tp, err
+	}
+
+	if n&NormalizedCase != 0 {
+		atomic.AddUint64(&nz.counts.Case, 1)
+	}
+	if n&NormalizedWhitespace != 0 {
+		atomic.AddUint64(&nz.counts.Whitespace, 1)
+	}
+	atomic.AddUint64(&nz.counts.Total, 1)
+
+	return tp, nil
+}
+
+// Counts returns the number of values accepted only after normalization so far.
+func (nz *Normalizer) Counts() NormalizationCounts {
+	return NormalizationCounts{
+		Case:       atomic.LoadUint64(&nz.counts.Case),
+		Whitespace: atomic.LoadUint64(&nz.counts.Whitespace),
+		Total:      atomic.LoadUint64(&nz.counts.Total),
+	}
+}
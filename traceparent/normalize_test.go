@@ -0,0 +1,73 @@
+package traceparent_test
+
+import (
+	"strings"
+	"sync"
+
+	. "github.com/lightstep/tracecontext.go/traceparent"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Normalization", func() {
+	const encoded = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	It("canonicalizes uppercase and surrounding whitespace", func() {
+		for s, expected := range map[string]Normalization{
+			encoded:                          0,
+			strings.ToUpper(encoded):         NormalizedCase,
+			" \t" + encoded + " ":            NormalizedWhitespace,
+			strings.ToUpper(encoded) + "\t ": NormalizedCase | NormalizedWhitespace,
+		} {
+			normalized, n := Normalize(s)
+			Expect(normalized).To(Equal(encoded))
+			Expect(n).To(Equal(expected))
+		}
+	})
+
+	It("does not change how strict parsing behaves", func() {
+		_, err := ParseString(strings.ToUpper(encoded))
+		Expect(err).To(MatchError("tracecontext: Invalid traceparent format"))
+
+		_, err = ParseString(encoded + " ")
+		Expect(err).To(MatchError("tracecontext: Invalid traceparent format"))
+	})
+
+	It("accepts normalized values and counts them", func() {
+		var nz Normalizer
+
+		expected, err := ParseString(encoded)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, s := range []string{encoded, strings.ToUpper(encoded), encoded + " ", " " + strings.ToUpper(encoded)} {
+			tp, err := nz.ParseString(s)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tp).To(Equal(expected))
+		}
+
+		_, err = nz.Parse([]byte(" 00-4BF92F3577B34DA6A3CE929D0E0E4736-0000000000000000-01"))
+		Expect(err).To(MatchError("tracecontext: Invalid traceparent span ID"))
+
+		Expect(nz.Counts()).To(Equal(NormalizationCounts{Case: 2, Whitespace: 2, Total: 3}))
+	})
+
+	It("counts concurrently", func() {
+		var nz Normalizer
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					_, err := nz.ParseString(strings.ToUpper(encoded))
+					Expect(err).NotTo(HaveOccurred())
+				}
+			}()
+		}
+		wg.Wait()
+
+		Expect(nz.Counts().Case).To(Equal(uint64(800)))
+	})
+})
@@ -33,11 +33,16 @@ const (
 	numTraceIDBytes = 16
 	numSpanIDBytes  = 8
 	numFlagBytes    = 1
+
+	flagRecorded = 1
+	flagRandom   = 2
 )
 
 var (
 	re = regexp.MustCompile(`^([a-f0-9]{2})-([a-f0-9]{32})-([a-f0-9]{16})-([a-f0-9]{2})(-.*)?$`)
 
+	lowerHexRe = regexp.MustCompile(`^[a-f0-9]*$`)
+
 	invalidTraceIDAllZeroes = make([]byte, numTraceIDBytes, numTraceIDBytes)
 	invalidSpanIDAllZeroes  = make([]byte, numSpanIDBytes, numSpanIDBytes)
 )
@@ -48,15 +53,25 @@ type Flags struct {
 	// Tracing systems are advised to record all new spans in recorded traces, as incomplete traces may lead to
 	// a degraded tracing experience.
 	Recorded bool
+	// Random indicates that at least the rightmost 7 bytes of the trace ID were generated randomly,
+	// so that they may be used as a source of randomness, e.g., for consistent probability sampling.
+	Random bool
 }
 
 // String encodes the Flags in an 8-bit field.
 func (f Flags) String() string {
-	var flags [1]byte
+	return fmt.Sprintf("%02x", f.encode())
+}
+
+func (f Flags) encode() byte {
+	var flags byte
 	if f.Recorded {
-		flags[0] = 1
+		flags |= flagRecorded
+	}
+	if f.Random {
+		flags |= flagRandom
 	}
-	return fmt.Sprintf("%02x", flags)
+	return flags
 }
 
 // TraceParent indicates information about a span and the trace of which it is part,
@@ -67,11 +82,11 @@ type TraceParent struct {
 	Version uint8
 	// TraceID is the trace ID of the whole trace, and should be constant across all spans in a given trace.
 	// A `TraceID` that contains only 0 bytes should be treated as invalid.
-	TraceID [16]byte
+	TraceID TraceID
 	// SpanID is the span ID of the span from which the `TraceParent` was derived, i.e., the parent of the next span that will be started.
 	// Span IDs should be unique within a given trace.
-	// A `TraceID` that contains only 0 bytes should be treated as invalid.
-	SpanID [8]byte
+	// A `SpanID` that contains only 0 bytes should be treated as invalid.
+	SpanID SpanID
 	// Flags indicate behaviour that is recommended when handling new spans.
 	Flags Flags
 }
@@ -79,7 +94,7 @@ type TraceParent struct {
 // String encodes the `TraceParent` into a string formatted according to the W3C spec.
 // The string may be invalid if any fields are invalid, e.g., if the `TraceID` contains only 0 bytes.
 func (tp TraceParent) String() string {
-	return fmt.Sprintf("%02x-%032x-%016x-%s", tp.Version, tp.TraceID, tp.SpanID, tp.Flags)
+	return fmt.Sprintf("%02x-%s-%s-%s", tp.Version, tp.TraceID, tp.SpanID, tp.Flags)
 }
 
 // Parse attempts to decode a `TraceParent` from a byte array.
@@ -110,12 +125,12 @@ func parse(b []byte) (tp TraceParent, err error) {
 		return
 	}
 
-	var traceID [16]byte
+	var traceID TraceID
 	if traceID, err = parseTraceID(matches[2]); err != nil {
 		return
 	}
 
-	var spanID [8]byte
+	var spanID SpanID
 	if spanID, err = parseSpanID(matches[3]); err != nil {
 		return
 	}
@@ -144,7 +159,7 @@ func parseVersion(b []byte) (uint8, error) {
 	return version[0], nil
 }
 
-func parseTraceID(b []byte) (traceID [16]byte, err error) {
+func parseTraceID(b []byte) (traceID TraceID, err error) {
 	id, ok := parseEncodedSegment(b, numTraceIDBytes)
 	if !ok {
 		return traceID, ErrInvalidFormat
@@ -158,7 +173,7 @@ func parseTraceID(b []byte) (traceID [16]byte, err error) {
 	return traceID, nil
 }
 
-func parseSpanID(b []byte) (spanID [8]byte, err error) {
+func parseSpanID(b []byte) (spanID SpanID, err error) {
 	id, ok := parseEncodedSegment(b, numSpanIDBytes)
 	if !ok {
 		return spanID, ErrInvalidFormat
@@ -178,9 +193,14 @@ func parseFlags(b []byte) (Flags, error) {
 		return Flags{}, ErrInvalidFormat
 	}
 
+	return decodeFlags(flags[0]), nil
+}
+
+func decodeFlags(flags byte) Flags {
 	return Flags{
-		Recorded: (flags[0] & 1) == 1,
-	}, nil
+		Recorded: (flags & flagRecorded) == flagRecorded,
+		Random:   (flags & flagRandom) == flagRandom,
+	}
 }
 
 func parseEncodedSegment(src []byte, expectedLen int) ([]byte, bool) {
@@ -0,0 +1,76 @@
+package traceparent
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+const (
+	// numRawBytes is the length of the binary form stored by `Binary`: the version, trace ID, span ID and flags, without field IDs.
+	numRawBytes = numVersionBytes + numTraceIDBytes + numSpanIDBytes + numFlagBytes
+)
+
+// Binary wraps a `TraceParent` so that it is stored in a database in a compact binary form rather than as text.
+// The binary form is 26 bytes: the version, trace ID, span ID and flags, in that order.
+// Unlike `MarshalBinary`, it has no field IDs, so it does not change if the W3C binary format does.
+type Binary struct {
+	TraceParent
+}
+
+// Value implements `driver.Valuer`, storing the `TraceParent` in its 26-byte binary form.
+func (b Binary) Value() (driver.Value, error) {
+	raw := make([]byte, 0, numRawBytes)
+	raw = append(raw, b.Version)
+	raw = append(raw, b.TraceID[:]...)
+	raw = append(raw, b.SpanID[:]...)
+	raw = append(raw, b.Flags.encode())
+	return raw, nil
+}
+
+// Value implements `driver.Valuer`, storing the `TraceParent` as text.
+// Use `Binary` to store it in its binary form instead, and a nil `*TraceParent` to store NULL.
+func (tp TraceParent) Value() (driver.Value, error) {
+	return tp.String(), nil
+}
+
+// Scan implements `sql.Scanner`.
+// It accepts the text form as a string or byte slice, or the binary form stored by `Binary` as exactly 26 bytes.
+// As with `Parse`, NULL is invalid; scan into a `*TraceParent` to allow it.
+// The `TraceParent` is left unchanged if there is an error.
+func (tp *TraceParent) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return ErrInvalidFormat
+	case string:
+		return tp.UnmarshalText([]byte(src))
+	case []byte:
+		if len(src) == numRawBytes {
+			return tp.scanRaw(src)
+		}
+		return tp.UnmarshalText(src)
+	default:
+		return fmt.Errorf("tracecontext: Cannot scan %T into traceparent", src)
+	}
+}
+
+func (tp *TraceParent) scanRaw(b []byte) error {
+	if b[0] > maxVersion {
+		return ErrInvalidVersion
+	}
+	b = b[numVersionBytes:]
+
+	traceID, b := b[:numTraceIDBytes], b[numTraceIDBytes:]
+	if isZero(traceID) {
+		return ErrInvalidTraceID
+	}
+	spanID, b := b[:numSpanIDBytes], b[numSpanIDBytes:]
+	if isZero(spanID) {
+		return ErrInvalidSpanID
+	}
+
+	tp.Version = Version
+	copy(tp.TraceID[:], traceID)
+	copy(tp.SpanID[:], spanID)
+	tp.Flags = decodeFlags(b[0])
+	return nil
+}
@@ -0,0 +1,85 @@
+package traceparent_test
+
+import (
+	"encoding/hex"
+
+	. "github.com/lightstep/tracecontext.go/traceparent"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SQL", func() {
+	const (
+		encoded = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+		raw     = "00" + "0af7651916cd43dd8448eb211c80319c" + "b7ad6b7169203331" + "01"
+	)
+
+	var tp TraceParent
+	var rawBytes []byte
+	BeforeEach(func() {
+		var err error
+		tp, err = ParseString(encoded)
+		Expect(err).NotTo(HaveOccurred())
+		rawBytes, err = hex.DecodeString(raw)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("stores the traceparent as text or binary", func() {
+		v, err := tp.Value()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(encoded))
+
+		v, err = Binary{tp}.Value()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(rawBytes))
+	})
+
+	It("scans text and binary forms", func() {
+		for _, src := range []interface{}{encoded, []byte(encoded), rawBytes} {
+			var scanned TraceParent
+			Expect(scanned.Scan(src)).To(Succeed())
+			Expect(scanned).To(Equal(tp))
+		}
+
+		var scanned Binary
+		Expect(scanned.Scan(rawBytes)).To(Succeed())
+		Expect(scanned.TraceParent).To(Equal(tp))
+	})
+
+	It("round-trips the binary form", func() {
+		v, err := Binary{tp}.Value()
+		Expect(err).NotTo(HaveOccurred())
+
+		var scanned Binary
+		Expect(scanned.Scan(v)).To(Succeed())
+		Expect(scanned.TraceParent).To(Equal(tp))
+	})
+
+	It("does not scan the W3C binary format", func() {
+		b, err := tp.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+
+		var scanned TraceParent
+		Expect(scanned.Scan(b)).To(MatchError("tracecontext: Invalid traceparent format"))
+	})
+
+	It("applies the same validation as parsing", func() {
+		var scanned TraceParent
+		Expect(scanned.Scan(nil)).To(MatchError("tracecontext: Invalid traceparent format"))
+		Expect(scanned.Scan("")).To(MatchError("tracecontext: Invalid traceparent format"))
+		Expect(scanned.Scan(42)).To(MatchError("tracecontext: Cannot scan int into traceparent"))
+
+		b := append([]byte{}, rawBytes...)
+		b[0] = 0xff
+		Expect(scanned.Scan(b)).To(MatchError("tracecontext: Invalid traceparent version"))
+
+		b = append([]byte{}, rawBytes...)
+		copy(b[1:17], make([]byte, 16))
+		Expect(scanned.Scan(b)).To(MatchError("tracecontext: Invalid traceparent trace ID"))
+
+		b = append([]byte{}, rawBytes...)
+		copy(b[17:25], make([]byte, 8))
+		Expect(scanned.Scan(b)).To(MatchError("tracecontext: Invalid traceparent span ID"))
+		Expect(scanned).To(Equal(TraceParent{}))
+	})
+})
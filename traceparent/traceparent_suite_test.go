@@ -26,10 +26,13 @@ func TestTraceparent(t *testing.T) {
 
 var _ = Describe("#String", func() {
 	It("returns a correctly formatted string", func() {
-		quick.Check(func(version byte, traceID [16]byte, spanID [8]byte, recorded bool) bool {
+		quick.Check(func(version byte, traceID [16]byte, spanID [8]byte, recorded bool, random bool) bool {
 			var flags [1]byte
 			if recorded {
-				flags[0] = 1
+				flags[0] |= 1
+			}
+			if random {
+				flags[0] |= 2
 			}
 
 			tp := TraceParent{
@@ -38,6 +41,7 @@ var _ = Describe("#String", func() {
 				SpanID:  spanID,
 				Flags: Flags{
 					Recorded: recorded,
+					Random:   random,
 				},
 			}
 			expected := string(encodeTraceParent([]byte{version}, traceID[:], spanID[:], flags[:]))
@@ -73,12 +77,15 @@ func testParsing(parse func(string) (TraceParent, error)) {
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(tp.Version).To(Equal(uint8(0)))
-			Expect(tp.TraceID).To(Equal(traceID))
-			Expect(tp.SpanID).To(Equal(spanID))
+			Expect(tp.TraceID).To(Equal(TraceID(traceID)))
+			Expect(tp.SpanID).To(Equal(SpanID(spanID)))
 
 			recorded := (flags[0] & 1) == 1
 			Expect(tp.Flags.Recorded).To(Equal(recorded))
 
+			random := (flags[0] & 2) == 2
+			Expect(tp.Flags.Random).To(Equal(random))
+
 			return true
 		}, nil)
 	})
@@ -340,3 +347,11 @@ func testParsing(parse func(string) (TraceParent, error)) {
 func encodeTraceParent(version []byte, traceID []byte, spanID []byte, flags []byte) string {
 	return fmt.Sprintf("%s-%s-%s-%s", hex.EncodeToString(version), hex.EncodeToString(traceID), hex.EncodeToString(spanID), hex.EncodeToString(flags))
 }
+
+// zeroTraceIDBinary returns the binary form of the `TraceParent` with its trace ID replaced by zeroes.
+func zeroTraceIDBinary(tp TraceParent) []byte {
+	b, err := tp.MarshalBinary()
+	Expect(err).NotTo(HaveOccurred())
+	copy(b[2:18], invalidTraceIDAllZeroes[:])
+	return b
+}
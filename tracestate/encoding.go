@@ -0,0 +1,92 @@
+package tracestate
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// MarshalText implements `encoding.TextMarshaler`, encoding the `Member` as by `String`.
+func (m Member) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+// It returns `ErrInvalidListMember` if the text is not a single, correctly formatted list member.
+func (m *Member) UnmarshalText(text []byte) error {
+	parsed, err := parseMember(string(text))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// MarshalJSON implements `json.Marshaler`, encoding the `Member` as a JSON string.
+func (m Member) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`, decoding a JSON string as by `UnmarshalText`.
+// It leaves the `Member` unchanged for `null`.
+func (m *Member) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONString(data, m.UnmarshalText)
+}
+
+// MarshalBinary implements `encoding.BinaryMarshaler`, encoding the `Member` as by `MarshalText`.
+func (m Member) MarshalBinary() ([]byte, error) {
+	return m.MarshalText()
+}
+
+// UnmarshalBinary implements `encoding.BinaryUnmarshaler`, decoding the `Member` as by `UnmarshalText`.
+func (m *Member) UnmarshalBinary(data []byte) error {
+	return m.UnmarshalText(data)
+}
+
+// MarshalText implements `encoding.TextMarshaler`, encoding the `TraceState` as by `String`.
+func (ts TraceState) MarshalText() ([]byte, error) {
+	return []byte(ts.String()), nil
+}
+
+// UnmarshalText implements `encoding.TextUnmarshaler`.
+// It returns an error under the same conditions as `Parse`.
+func (ts *TraceState) UnmarshalText(text []byte) error {
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*ts = parsed
+	return nil
+}
+
+// MarshalJSON implements `json.Marshaler`, encoding the `TraceState` as a JSON string.
+func (ts TraceState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ts.String())
+}
+
+// UnmarshalJSON implements `json.Unmarshaler`, decoding a JSON string as by `UnmarshalText`.
+// It leaves the `TraceState` unchanged for `null`.
+func (ts *TraceState) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONString(data, ts.UnmarshalText)
+}
+
+// MarshalBinary implements `encoding.BinaryMarshaler`, encoding the `TraceState` as by `MarshalText`.
+func (ts TraceState) MarshalBinary() ([]byte, error) {
+	return ts.MarshalText()
+}
+
+// UnmarshalBinary implements `encoding.BinaryUnmarshaler`, decoding the `TraceState` as by `UnmarshalText`.
+func (ts *TraceState) UnmarshalBinary(data []byte) error {
+	return ts.UnmarshalText(data)
+}
+
+func unmarshalJSONString(data []byte, unmarshalText func([]byte) error) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return unmarshalText([]byte(s))
+}
@@ -0,0 +1,54 @@
+package tracestate_test
+
+import (
+	"encoding/json"
+
+	. "github.com/lightstep/tracecontext.go/tracestate"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Encoding", func() {
+	ts := TraceState{member("vendor@tenant", "a b"), member("other", "1")}
+
+	It("round-trips through text, binary and JSON", func() {
+		text, err := ts.MarshalText()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(text)).To(Equal("vendor@tenant=a b,other=1"))
+		var fromText TraceState
+		Expect(fromText.UnmarshalText(text)).To(Succeed())
+		Expect(fromText).To(Equal(ts))
+
+		b, err := ts.MarshalBinary()
+		Expect(err).NotTo(HaveOccurred())
+		var fromBinary TraceState
+		Expect(fromBinary.UnmarshalBinary(b)).To(Succeed())
+		Expect(fromBinary).To(Equal(ts))
+
+		j, err := json.Marshal(ts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(j)).To(Equal(`"vendor@tenant=a b,other=1"`))
+		var fromJSON TraceState
+		Expect(json.Unmarshal(j, &fromJSON)).To(Succeed())
+		Expect(fromJSON).To(Equal(ts))
+	})
+
+	It("encodes members individually", func() {
+		j, err := json.Marshal(ts[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(j)).To(Equal(`"vendor@tenant=a b"`))
+
+		var m Member
+		Expect(json.Unmarshal(j, &m)).To(Succeed())
+		Expect(m).To(Equal(ts[0]))
+	})
+
+	It("applies the same validation as parsing", func() {
+		var decoded TraceState
+		Expect(decoded.UnmarshalText([]byte("a=1,a=2"))).To(MatchError("tracecontext: Duplicate list member key in tracestate"))
+		Expect(json.Unmarshal([]byte(`"A=1"`), &decoded)).To(MatchError("tracecontext: Invalid tracestate list member"))
+
+		var m Member
+		Expect(m.UnmarshalText([]byte("a=1,b=2"))).To(MatchError("tracecontext: Invalid tracestate list member"))
+	})
+})
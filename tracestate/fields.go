@@ -0,0 +1,233 @@
+package tracestate
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidField occurs if a structured value contains an incorrectly formatted `key:value` field.
+	ErrInvalidField = errors.New("tracecontext: Invalid tracestate value field")
+	// ErrDuplicateFieldKey occurs if at least two fields of a structured value contain the same key.
+	ErrDuplicateFieldKey = errors.New("tracecontext: Duplicate field key in tracestate value")
+	// ErrFieldNotFound occurs if a structured value does not contain a field with the requested key.
+	ErrFieldNotFound = errors.New("tracecontext: Field not found in tracestate value")
+)
+
+const (
+	fieldDelimiter         = ";"
+	fieldKeyValueDelimiter = ":"
+)
+
+var (
+	fieldKeyRe   = regexp.MustCompile(`^[\x21-\x2b\x2d-\x39\x3c\x3e-\x7e]+$`)
+	fieldValueRe = regexp.MustCompile(`^[\x20-\x2b\x2d-\x3a\x3c\x3e-\x7e]*$`)
+)
+
+// Field is a single `key:value` pair of a structured list member value.
+type Field struct {
+	// Key identifies the field within the value, e.g., `th`.
+	Key string
+	// Value is the data associated with the key. It may be empty.
+	Value string
+}
+
+// String encodes the `Field` as `key:value`.
+func (f Field) String() string {
+	return f.Key + fieldKeyValueDelimiter + f.Value
+}
+
+// Fields represents a list member value that packs several `Field`s together, delimited by `;`,
+// e.g., `th:8;rv:1a2b3c4d5e6f70`.
+// The order of the fields is preserved when encoding.
+type Fields []Field
+
+// ParseFields attempts to decode `Fields` from a list member value.
+// It returns an error if a field is incorrectly formatted, if a key occurs more than once,
+// or if the value is not a valid list member value.
+func ParseFields(value string) (Fields, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if err := ValidateValue(value); err != nil {
+		return nil, err
+	}
+
+	var fields Fields
+	for _, s := range strings.Split(value, fieldDelimiter) {
+		i := strings.Index(s, fieldKeyValueDelimiter)
+		if i < 0 {
+			return nil, ErrInvalidField
+		}
+
+		f := Field{
+			Key:   s[:i],
+			Value: s[i+1:],
+		}
+		if err := validateField(f); err != nil {
+			return nil, err
+		}
+		if _, ok := fields.Get(f.Key); ok {
+			return nil, ErrDuplicateFieldKey
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// String encodes the `Fields` into a single list member value.
+// The string may be invalid if any fields are invalid, or if the encoded value is too long; use `Validate` to check.
+func (fs Fields) String() string {
+	var fields []string
+	for _, f := range fs {
+		fields = append(fields, f.String())
+	}
+	return strings.Join(fields, fieldDelimiter)
+}
+
+// Validate checks that every `Field` is valid, that no two `Field`s share a key,
+// and that the encoded value is a valid list member value.
+// It returns `ErrInvalidValue` if the encoded value is invalid, e.g., longer than 256 characters.
+func (fs Fields) Validate() error {
+	found := make(map[string]interface{})
+	for _, f := range fs {
+		if err := validateField(f); err != nil {
+			return err
+		}
+		if _, ok := found[f.Key]; ok {
+			return ErrDuplicateFieldKey
+		}
+		found[f.Key] = nil
+	}
+
+	if len(fs) == 0 {
+		return nil
+	}
+
+	return ValidateValue(fs.String())
+}
+
+// Get returns the value of the field with the given key, and whether such a field exists.
+func (fs Fields) Get(key string) (string, bool) {
+	for _, f := range fs {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+// GetUint returns the value of the field with the given key, decoded as an unsigned integer in the given base.
+// It returns `ErrFieldNotFound` if there is no such field, or `ErrInvalidField` if the value is not an integer.
+func (fs Fields) GetUint(key string, base int) (uint64, error) {
+	s, ok := fs.Get(key)
+	if !ok {
+		return 0, ErrFieldNotFound
+	}
+	v, err := strconv.ParseUint(s, base, 64)
+	if err != nil {
+		return 0, ErrInvalidField
+	}
+	return v, nil
+}
+
+// GetFloat returns the value of the field with the given key, decoded as a floating-point number.
+// It returns `ErrFieldNotFound` if there is no such field, or `ErrInvalidField` if the value is not a number.
+func (fs Fields) GetFloat(key string) (float64, error) {
+	s, ok := fs.Get(key)
+	if !ok {
+		return 0, ErrFieldNotFound
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, ErrInvalidField
+	}
+	return v, nil
+}
+
+// GetBool returns the value of the field with the given key, decoded as a boolean, e.g., `1` or `true`.
+// It returns `ErrFieldNotFound` if there is no such field, or `ErrInvalidField` if the value is not a boolean.
+func (fs Fields) GetBool(key string) (bool, error) {
+	s, ok := fs.Get(key)
+	if !ok {
+		return false, ErrFieldNotFound
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, ErrInvalidField
+	}
+	return v, nil
+}
+
+// Set sets the value of the field with the given key, replacing an existing field in place or appending a new one.
+// It returns an error, and leaves the `Fields` unchanged, if the field is invalid or the encoded value would become invalid.
+func (fs *Fields) Set(key, value string) error {
+	f := Field{
+		Key:   key,
+		Value: value,
+	}
+	if err := validateField(f); err != nil {
+		return err
+	}
+
+	updated := make(Fields, 0, len(*fs)+1)
+	replaced := false
+	for _, existing := range *fs {
+		if existing.Key == key {
+			existing = f
+			replaced = true
+		}
+		updated = append(updated, existing)
+	}
+	if !replaced {
+		updated = append(updated, f)
+	}
+
+	if err := updated.Validate(); err != nil {
+		return err
+	}
+
+	*fs = updated
+	return nil
+}
+
+// SetUint sets the value of the field with the given key to an unsigned integer encoded in the given base.
+func (fs *Fields) SetUint(key string, v uint64, base int) error {
+	return fs.Set(key, strconv.FormatUint(v, base))
+}
+
+// SetFloat sets the value of the field with the given key to a floating-point number,
+// encoded using the fewest digits necessary to represent it exactly.
+func (fs *Fields) SetFloat(key string, v float64) error {
+	return fs.Set(key, strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+// SetBool sets the value of the field with the given key to `1` or `0`.
+func (fs *Fields) SetBool(key string, v bool) error {
+	if v {
+		return fs.Set(key, "1")
+	}
+	return fs.Set(key, "0")
+}
+
+// Delete removes the field with the given key, if it exists.
+func (fs *Fields) Delete(key string) {
+	var updated Fields
+	for _, f := range *fs {
+		if f.Key != key {
+			updated = append(updated, f)
+		}
+	}
+	*fs = updated
+}
+
+func validateField(f Field) error {
+	if !fieldKeyRe.MatchString(f.Key) || !fieldValueRe.MatchString(f.Value) {
+		return ErrInvalidField
+	}
+	return nil
+}
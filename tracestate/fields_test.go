@@ -0,0 +1,112 @@
+package tracestate_test
+
+import (
+	"strings"
+
+	. "github.com/lightstep/tracecontext.go/tracestate"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe(".ParseFields", func() {
+	It("parses structured values in order", func() {
+		fs, err := ParseFields("th:8;rv:1a2b3c4d5e6f70;x:")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fs).To(Equal(Fields{
+			{Key: "th", Value: "8"},
+			{Key: "rv", Value: "1a2b3c4d5e6f70"},
+			{Key: "x", Value: ""},
+		}))
+		Expect(fs.String()).To(Equal("th:8;rv:1a2b3c4d5e6f70;x:"))
+	})
+
+	It("allows values containing the key-value delimiter", func() {
+		fs, err := ParseFields("url:http://example.com")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fs).To(Equal(Fields{{Key: "url", Value: "http://example.com"}}))
+	})
+
+	It("returns no fields for an empty value", func() {
+		fs, err := ParseFields("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fs).To(BeEmpty())
+	})
+
+	It("errors if a field is incorrectly formatted", func() {
+		for _, value := range []string{"th", "th:8;", ";th:8", ":8", "t h:8", "th:8;;rv:1"} {
+			_, err := ParseFields(value)
+			Expect(err).To(MatchError("tracecontext: Invalid tracestate value field"), value)
+		}
+	})
+
+	It("errors if a key occurs more than once", func() {
+		_, err := ParseFields("th:8;th:c")
+		Expect(err).To(MatchError("tracecontext: Duplicate field key in tracestate value"))
+	})
+
+	It("errors if the value is not a valid list member value", func() {
+		_, err := ParseFields("th:8,rv:1")
+		Expect(err).To(MatchError("tracecontext: Invalid tracestate list member value"))
+
+		_, err = ParseFields("th:8 ")
+		Expect(err).To(MatchError("tracecontext: Invalid tracestate list member value"))
+	})
+
+	It("errors if the value is longer than 256 characters", func() {
+		_, err := ParseFields("k:" + strings.Repeat("a", 254))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = ParseFields("k:" + strings.Repeat("a", 255))
+		Expect(err).To(MatchError("tracecontext: Invalid tracestate list member value"))
+	})
+})
+
+var _ = Describe("Fields", func() {
+	It("gets and sets typed values", func() {
+		var fs Fields
+		Expect(fs.SetUint("th", 0x8, 16)).To(Succeed())
+		Expect(fs.SetFloat("p", 0.25)).To(Succeed())
+		Expect(fs.SetBool("s", true)).To(Succeed())
+		Expect(fs.String()).To(Equal("th:8;p:0.25;s:1"))
+
+		Expect(fs.GetUint("th", 16)).To(Equal(uint64(8)))
+		Expect(fs.GetFloat("p")).To(Equal(0.25))
+		Expect(fs.GetBool("s")).To(BeTrue())
+	})
+
+	It("replaces existing fields in place", func() {
+		fs, err := ParseFields("a:1;b:2;c:3")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fs.Set("b", "two")).To(Succeed())
+		Expect(fs.String()).To(Equal("a:1;b:two;c:3"))
+
+		fs.Delete("a")
+		Expect(fs.String()).To(Equal("b:two;c:3"))
+	})
+
+	It("errors if a requested field is missing or has the wrong type", func() {
+		fs := Fields{{Key: "th", Value: "zz"}}
+
+		_, err := fs.GetUint("rv", 16)
+		Expect(err).To(MatchError("tracecontext: Field not found in tracestate value"))
+		_, err = fs.GetUint("th", 16)
+		Expect(err).To(MatchError("tracecontext: Invalid tracestate value field"))
+		_, err = fs.GetFloat("th")
+		Expect(err).To(MatchError("tracecontext: Invalid tracestate value field"))
+		_, err = fs.GetBool("th")
+		Expect(err).To(MatchError("tracecontext: Invalid tracestate value field"))
+	})
+
+	It("leaves the fields unchanged if a set would make them invalid", func() {
+		fs := Fields{{Key: "a", Value: "1"}}
+
+		Expect(fs.Set("b", "x;y")).To(MatchError("tracecontext: Invalid tracestate value field"))
+		Expect(fs.Set("b", "trailing ")).To(MatchError("tracecontext: Invalid tracestate list member value"))
+		Expect(fs.Set("b", strings.Repeat("a", 256))).To(MatchError("tracecontext: Invalid tracestate list member value"))
+		Expect(fs.SetUint("b", 1<<63, 2)).To(Succeed())
+		Expect(fs.Validate()).To(Succeed())
+		Expect(fs.SetBool(strings.Repeat("k", 186), true)).To(MatchError("tracecontext: Invalid tracestate list member value"))
+		Expect(fs.String()).To(Equal("a:1;b:1" + strings.Repeat("0", 63)))
+	})
+})
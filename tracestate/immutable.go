@@ -0,0 +1,124 @@
+package tracestate
+
+import (
+	"strings"
+)
+
+// Immutable is a `TraceState` that cannot be modified in place, and so is safe to share between goroutines.
+// Its mutation methods return new instances that share the unchanged tail of the list with the original,
+// so that, e.g., deriving many child contexts from the same parent copies at most the members that precede the updated one.
+// The zero value is an empty `TraceState`.
+type Immutable struct {
+	head *node
+	len  int
+}
+
+// node is a list member in an `Immutable`. Nodes are never modified once they are reachable from an `Immutable`.
+type node struct {
+	member Member
+	next   *node
+}
+
+// NewImmutable returns an `Immutable` with the same members as the `TraceState`.
+// It returns an error under the same conditions as `TraceState.Validate`.
+func NewImmutable(ts TraceState) (Immutable, error) {
+	if err := ts.Validate(); err != nil {
+		return Immutable{}, err
+	}
+
+	var im Immutable
+	for i := len(ts) - 1; i >= 0; i-- {
+		im = im.push(ts[i])
+	}
+	return im, nil
+}
+
+// ParseImmutable attempts to decode an `Immutable` from a string.
+// It returns an error under the same conditions as `ParseString`.
+func ParseImmutable(s string) (Immutable, error) {
+	ts, err := ParseString(s)
+	if err != nil {
+		return Immutable{}, err
+	}
+	return NewImmutable(ts)
+}
+
+func (im Immutable) push(m Member) Immutable {
+	return Immutable{head: &node{member: m, next: im.head}, len: im.len + 1}
+}
+
+// Len returns the number of list members.
+func (im Immutable) Len() int {
+	return im.len
+}
+
+// Get returns the `Member` with the given key, and whether such a `Member` exists.
+func (im Immutable) Get(key string) (Member, bool) {
+	for n := im.head; n != nil; n = n.next {
+		if n.member.Key() == key {
+			return n.member, true
+		}
+	}
+	return Member{}, false
+}
+
+// Set returns a new `Immutable` with the given `Member` at the front of the list,
+// replacing any existing `Member` with the same key, as `TraceState.Set` does.
+// It returns an error, and the original `Immutable`, if the `Member` is invalid or the list would contain too many members.
+func (im Immutable) Set(m Member) (Immutable, error) {
+	if err := m.Validate(); err != nil {
+		return im, err
+	}
+
+	rest, found := im.without(m.Key())
+	if !found && im.len >= maxMembers {
+		return im, ErrTooManyListMembers
+	}
+	return rest.push(m), nil
+}
+
+// Delete returns a new `Immutable` without the `Member` with the given key.
+func (im Immutable) Delete(key string) Immutable {
+	rest, _ := im.without(key)
+	return rest
+}
+
+// without returns the list without the member with the given key, copying the members that precede it and sharing those that follow,
+// and whether such a member was found. The original list is returned if it was not.
+func (im Immutable) without(key string) (Immutable, bool) {
+	var prefix []Member
+	for n := im.head; n != nil; n = n.next {
+		if n.member.Key() != key {
+			prefix = append(prefix, n.member)
+			continue
+		}
+
+		rest := Immutable{head: n.next, len: im.len - len(prefix) - 1}
+		for i := len(prefix) - 1; i >= 0; i-- {
+			rest = rest.push(prefix[i])
+		}
+		return rest, true
+	}
+	return im, false
+}
+
+// TraceState returns the members as a new `TraceState`, which the caller may modify freely.
+func (im Immutable) TraceState() TraceState {
+	if im.len == 0 {
+		return nil
+	}
+	ts := make(TraceState, 0, im.len)
+	for n := im.head; n != nil; n = n.next {
+		ts = append(ts, n.member)
+	}
+	return ts
+}
+
+// String encodes all members into a single string, formatted according to the W3C spec.
+func (im Immutable) String() string {
+	members := make([]string, 0, im.len)
+	for n := im.head; n != nil; n = n.next {
+		members = append(members, n.member.String())
+	}
+	return strings.Join(members, delimiter)
+}
@@ -0,0 +1,110 @@
+package tracestate_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	. "github.com/lightstep/tracecontext.go/tracestate"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Immutable", func() {
+	It("behaves as TraceState does without modifying the original", func() {
+		ts := TraceState{member("a", "1"), member("b", "2"), member("t@b", "3")}
+		im, err := NewImmutable(ts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(im.Len()).To(Equal(3))
+		Expect(im.String()).To(Equal(ts.String()))
+		Expect(im.TraceState()).To(Equal(ts))
+
+		updated, err := im.Set(member("b", "4"))
+		Expect(err).NotTo(HaveOccurred())
+		expected, err := ts.Set(member("b", "4"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.TraceState()).To(Equal(expected))
+		Expect(updated.Delete("a").String()).To(Equal(expected.DeleteKey("a").String()))
+		Expect(updated.Delete("missing")).To(Equal(updated))
+
+		Expect(im.String()).To(Equal("a=1,b=2,t@b=3"))
+
+		m, ok := updated.Get("t@b")
+		Expect(ok).To(BeTrue())
+		Expect(m.Value).To(Equal("3"))
+		_, ok = updated.Get("t")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("does not share the TraceState it returns", func() {
+		im, err := ParseImmutable("a=1,b=2")
+		Expect(err).NotTo(HaveOccurred())
+
+		ts := im.TraceState()
+		ts[0].Value = "changed"
+		_ = append(ts[:1], member("c", "3"))
+		Expect(im.String()).To(Equal("a=1,b=2"))
+	})
+
+	It("errors under the same conditions as TraceState", func() {
+		_, err := ParseImmutable("a=1,a=2")
+		Expect(err).To(MatchError("tracecontext: Duplicate list member key in tracestate"))
+		_, err = NewImmutable(TraceState{{SystemID: "A", Value: "1"}})
+		Expect(err).To(MatchError("tracecontext: Invalid tracestate list member key"))
+
+		var im Immutable
+		Expect(im.String()).To(BeEmpty())
+		_, err = im.Set(Member{SystemID: "a", Value: "1 "})
+		Expect(err).To(MatchError("tracecontext: Invalid tracestate list member value"))
+
+		for i := 0; i < 32; i++ {
+			im, err = im.Set(member(fmt.Sprintf("vendor%d", i), "value"))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		_, err = im.Set(member("vendor0", "updated"))
+		Expect(err).NotTo(HaveOccurred())
+		full, err := im.Set(member("vendor", "value"))
+		Expect(err).To(MatchError("tracecontext: Too many list members in tracestate"))
+		Expect(full).To(Equal(im))
+	})
+
+	// These specs are only meaningful when run with the race detector, i.e., `go test -race`.
+	It("is safe to derive from concurrently", func() {
+		var members []string
+		for i := 0; i < 16; i++ {
+			members = append(members, fmt.Sprintf("vendor%d=%d", i, i))
+		}
+		parent, err := ParseImmutable(strings.Join(members, ","))
+		Expect(err).NotTo(HaveOccurred())
+		original := parent.String()
+
+		const goroutines = 64
+		results := make([]string, goroutines)
+		var wg sync.WaitGroup
+		for i := 0; i < goroutines; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer GinkgoRecover()
+
+				child, err := parent.Set(member(fmt.Sprintf("vendor%d", i%20), fmt.Sprintf("child%d", i)))
+				Expect(err).NotTo(HaveOccurred())
+				child = child.Delete(fmt.Sprintf("vendor%d", (i+1)%16))
+				child, err = child.Set(member(fmt.Sprintf("child%d@own", i), "x"))
+				Expect(err).NotTo(HaveOccurred())
+				results[i] = child.String()
+			}(i)
+		}
+		wg.Wait()
+
+		Expect(parent.String()).To(Equal(original))
+		for i, result := range results {
+			ts, err := ParseString(result)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ts[0].Key()).To(Equal(fmt.Sprintf("child%d@own", i)))
+			Expect(ts[1].Value).To(Equal(fmt.Sprintf("child%d", i)))
+			_, ok := ts.GetKey(fmt.Sprintf("vendor%d", (i+1)%16))
+			Expect(ok).To(BeFalse())
+		}
+	})
+})
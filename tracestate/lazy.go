@@ -0,0 +1,121 @@
+package tracestate
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Lazy holds an encoded `tracestate` and parses it only when a member is accessed or the list is modified,
+// so that services that merely propagate the `tracestate` avoid the cost of parsing and re-encoding it.
+//
+// Until it is modified, a `Lazy` encodes as the original value, even if that value is invalid.
+// Once parsed, an invalid value is treated as an empty `TraceState`, as `FromHeaders` does; use `Validate` to check for this.
+// The zero value is an empty `TraceState`.
+//
+// The value is parsed at most once, even by copies of the `Lazy`, so the read methods may be called concurrently,
+// e.g., on a `Lazy` shared through a request context. As with a map, `Set` and `Delete` must not be called concurrently
+// with any other method; modify a copy instead.
+type Lazy struct {
+	raw    string
+	parsed *lazyParse
+
+	modified bool
+	ts       TraceState
+}
+
+// lazyParse holds the result of parsing the encoded value, shared between copies of a `Lazy`.
+type lazyParse struct {
+	once sync.Once
+	done uint32
+	ts   TraceState
+	err  error
+}
+
+// NewLazy returns a `Lazy` for an encoded `tracestate`, e.g., the comma-joined values of the `tracestate` headers.
+func NewLazy(raw string) Lazy {
+	return Lazy{raw: raw, parsed: &lazyParse{}}
+}
+
+// LazyFromTraceState returns a `Lazy` that holds an already decoded `TraceState`.
+func LazyFromTraceState(ts TraceState) Lazy {
+	return Lazy{modified: true, ts: ts}
+}
+
+// parse returns the decoded original value, parsing it on first use.
+func (l *Lazy) parse() (TraceState, error) {
+	if l.parsed == nil {
+		return nil, nil
+	}
+	p := l.parsed
+	p.once.Do(func() {
+		p.ts, p.err = ParseString(l.raw)
+		if p.err != nil {
+			p.ts = nil
+		}
+		atomic.StoreUint32(&p.done, 1)
+	})
+	return p.ts, p.err
+}
+
+// Parsed reports whether the encoded value has been parsed.
+func (l *Lazy) Parsed() bool {
+	return l.modified || l.parsed != nil && atomic.LoadUint32(&l.parsed.done) == 1
+}
+
+// Modified reports whether the list has been modified since the `Lazy` was created.
+func (l *Lazy) Modified() bool {
+	return l.modified
+}
+
+// Validate parses the encoded value if necessary, returning the same error as `Parse` would.
+func (l *Lazy) Validate() error {
+	_, err := l.parse()
+	return err
+}
+
+// TraceState parses the encoded value if necessary and returns the decoded list, which is empty if the value is invalid.
+func (l *Lazy) TraceState() TraceState {
+	if l.modified {
+		return l.ts
+	}
+	ts, _ := l.parse()
+	return ts
+}
+
+// Len returns the number of list members.
+func (l *Lazy) Len() int {
+	return len(l.TraceState())
+}
+
+// Get returns the `Member` with the given key, and whether such a `Member` exists.
+func (l *Lazy) Get(key string) (Member, bool) {
+	return l.TraceState().GetKey(key)
+}
+
+// Set adds or updates the `Member`, moving it to the front of the list as `TraceState.Set` does.
+// It returns an error, leaving the list unmodified, if the `Member` is invalid or the list would contain too many members.
+func (l *Lazy) Set(m Member) error {
+	ts, err := l.TraceState().Set(m)
+	if err != nil {
+		return err
+	}
+	l.ts, l.modified = ts, true
+	return nil
+}
+
+// Delete removes the `Member` with the given key, if it exists.
+func (l *Lazy) Delete(key string) {
+	ts := l.TraceState()
+	if _, ok := ts.GetKey(key); !ok {
+		return
+	}
+	l.ts, l.modified = ts.DeleteKey(key), true
+}
+
+// String returns the original encoded value if the list has not been modified, and otherwise encodes the modified list.
+func (l *Lazy) String() string {
+	if !l.modified {
+		return l.raw
+	}
+	return l.ts.String()
+}
@@ -0,0 +1,97 @@
+package tracestate_test
+
+import (
+	"sync"
+
+	. "github.com/lightstep/tracecontext.go/tracestate"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lazy", func() {
+	It("emits the original value without parsing it if untouched", func() {
+		for _, raw := range []string{"", "a=1, b=2 ,,c@d=3", "Invalid=1,a=1,a=2"} {
+			l := NewLazy(raw)
+			Expect(l.String()).To(Equal(raw))
+			Expect(l.Parsed()).To(BeFalse())
+			Expect(l.Modified()).To(BeFalse())
+		}
+	})
+
+	It("parses only when a member is accessed, still emitting the original value", func() {
+		l := NewLazy("a=1, b@c=2")
+		m, ok := l.Get("b@c")
+		Expect(ok).To(BeTrue())
+		Expect(m).To(Equal(member("b@c", "2")))
+		Expect(l.Len()).To(Equal(2))
+		Expect(l.Parsed()).To(BeTrue())
+		Expect(l.String()).To(Equal("a=1, b@c=2"))
+
+		l.Delete("missing")
+		Expect(l.Modified()).To(BeFalse())
+	})
+
+	It("encodes the list once modified", func() {
+		l := NewLazy("a=1, b=2")
+		Expect(l.Set(member("b", "3"))).To(Succeed())
+		Expect(l.String()).To(Equal("b=3,a=1"))
+		Expect(l.Modified()).To(BeTrue())
+
+		l = NewLazy("a=1, b=2")
+		l.Delete("a")
+		Expect(l.String()).To(Equal("b=2"))
+
+		Expect(l.Set(Member{SystemID: "Invalid", Value: "1"})).To(MatchError("tracecontext: Invalid tracestate list member key"))
+		Expect(l.String()).To(Equal("b=2"))
+	})
+
+	It("validates as parsing does, treating invalid values as empty", func() {
+		l := NewLazy("a=1,a=2")
+		Expect(l.Validate()).To(MatchError("tracecontext: Duplicate list member key in tracestate"))
+		Expect(l.TraceState()).To(BeEmpty())
+		Expect(l.String()).To(Equal("a=1,a=2"))
+
+		Expect(l.Set(member("b", "1"))).To(Succeed())
+		Expect(l.String()).To(Equal("b=1"))
+
+		var zero Lazy
+		Expect(zero.Validate()).To(Succeed())
+		Expect(zero.String()).To(BeEmpty())
+
+		decoded := LazyFromTraceState(TraceState{member("a", "1")})
+		Expect(decoded.String()).To(Equal("a=1"))
+	})
+
+	It("parses once when read concurrently, including by copies", func() {
+		l := NewLazy("a=1, b@c=2")
+		copied := l
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(2)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(l.Validate()).To(Succeed())
+				Expect(l.Len()).To(Equal(2))
+				_, ok := l.Get("b@c")
+				Expect(ok).To(BeTrue())
+				Expect(l.String()).To(Equal("a=1, b@c=2"))
+			}()
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(copied.TraceState()).To(HaveLen(2))
+				copied.Parsed()
+			}()
+		}
+		wg.Wait()
+
+		Expect(l.Parsed()).To(BeTrue())
+		Expect(copied.Parsed()).To(BeTrue())
+		Expect(copied.Set(member("d", "4"))).To(Succeed())
+		Expect(copied.String()).To(Equal("d=4,a=1,b@c=2"))
+		Expect(l.String()).To(Equal("a=1, b@c=2"))
+		Expect(l.Len()).To(Equal(2))
+	})
+})
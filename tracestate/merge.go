@@ -0,0 +1,173 @@
+package tracestate
+
+const (
+	// largeMemberLength is the length above which list members are removed first when truncating, per the spec.
+	largeMemberLength = 128
+)
+
+// ConflictStrategy resolves two members with the same key, returning the member to keep.
+// Only the value of the returned `Member` is used.
+type ConflictStrategy func(existing, incoming Member) Member
+
+var (
+	// FirstWins keeps the member from the earliest `TraceState` passed to `Merge`.
+	FirstWins ConflictStrategy = func(existing, incoming Member) Member { return existing }
+	// MostRecentWins keeps the member from the latest `TraceState` passed to `Merge`.
+	MostRecentWins ConflictStrategy = func(existing, incoming Member) Member { return incoming }
+)
+
+// MergeOptions configure how `Merge` combines `TraceState`s.
+type MergeOptions struct {
+	// Default resolves conflicting members whose key has no entry in `Strategies`. It defaults to `FirstWins`.
+	Default ConflictStrategy
+	// Strategies resolve conflicting members by key, e.g., `congo` or `t61@congo`.
+	Strategies map[string]ConflictStrategy
+	// MaxMembers limits the number of members in the result. It defaults to, and may not exceed, the spec's limit of 32.
+	MaxMembers int
+	// MaxLength limits the length of the encoded result, if positive.
+	// As the spec requires, members longer than 128 characters are dropped first, and then members from the end of the list.
+	MaxLength int
+}
+
+// DropReason explains why `Merge` dropped a list member.
+type DropReason int
+
+const (
+	// DroppedConflict indicates that a member was not kept when resolving a conflict.
+	DroppedConflict DropReason = iota + 1
+	// DroppedInvalid indicates that a member, or the result of resolving a conflict, was invalid.
+	DroppedInvalid
+	// DroppedMemberLimit indicates that a member was dropped to respect `MaxMembers`.
+	DroppedMemberLimit
+	// DroppedSizeLimit indicates that a member was dropped to respect `MaxLength`.
+	DroppedSizeLimit
+)
+
+func (r DropReason) String() string {
+	switch r {
+	case DroppedConflict:
+		return "conflict"
+	case DroppedInvalid:
+		return "invalid"
+	case DroppedMemberLimit:
+		return "member limit"
+	case DroppedSizeLimit:
+		return "size limit"
+	default:
+		return "unknown"
+	}
+}
+
+// DroppedMember is a list member that `Merge` did not include in its result.
+type DroppedMember struct {
+	Member Member
+	Reason DropReason
+}
+
+// Merge combines `TraceState`s into one, e.g., for a unit of work that aggregates messages from many traces.
+// The states should be given from earliest to most recent.
+//
+// Members are ordered by the first appearance of their key across the states.
+// Members that share a key are resolved by the configured `ConflictStrategy`,
+// and the result is then truncated to respect the configured limits.
+// Merge returns every member that it did not include in the result, and why.
+func Merge(opts MergeOptions, states ...TraceState) (TraceState, []DroppedMember) {
+	var merged TraceState
+	var dropped []DroppedMember
+	index := make(map[string]int)
+
+	for _, ts := range states {
+		for _, incoming := range ts {
+			if incoming.Validate() != nil {
+				dropped = append(dropped, DroppedMember{Member: incoming, Reason: DroppedInvalid})
+				continue
+			}
+
+			key := incoming.Key()
+			i, ok := index[key]
+			if !ok {
+				index[key] = len(merged)
+				merged = append(merged, incoming)
+				continue
+			}
+
+			var resolved []DroppedMember
+			merged[i], resolved = resolve(opts.strategy(key), merged[i], incoming)
+			dropped = append(dropped, resolved...)
+		}
+	}
+
+	return opts.truncate(merged, dropped)
+}
+
+func (opts MergeOptions) strategy(key string) ConflictStrategy {
+	if s, ok := opts.Strategies[key]; ok && s != nil {
+		return s
+	}
+	if opts.Default != nil {
+		return opts.Default
+	}
+	return FirstWins
+}
+
+// resolve returns the member to keep, with the key of the existing member, and the members that were not kept.
+func resolve(strategy ConflictStrategy, existing, incoming Member) (Member, []DroppedMember) {
+	result := existing
+	result.Value = strategy(existing, incoming).Value
+
+	switch {
+	case ValidateValue(result.Value) != nil:
+		return existing, []DroppedMember{
+			{Member: result, Reason: DroppedInvalid},
+			{Member: incoming, Reason: DroppedConflict},
+		}
+	case result.Value == existing.Value:
+		return result, []DroppedMember{{Member: incoming, Reason: DroppedConflict}}
+	case result.Value == incoming.Value:
+		return result, []DroppedMember{{Member: existing, Reason: DroppedConflict}}
+	default:
+		return result, []DroppedMember{
+			{Member: existing, Reason: DroppedConflict},
+			{Member: incoming, Reason: DroppedConflict},
+		}
+	}
+}
+
+func (opts MergeOptions) truncate(ts TraceState, dropped []DroppedMember) (TraceState, []DroppedMember) {
+	limit := opts.MaxMembers
+	if limit <= 0 || limit > maxMembers {
+		limit = maxMembers
+	}
+	for len(ts) > limit {
+		dropped = append(dropped, DroppedMember{Member: ts[len(ts)-1], Reason: DroppedMemberLimit})
+		ts = ts[:len(ts)-1]
+	}
+
+	if opts.MaxLength <= 0 {
+		return ts, dropped
+	}
+
+	for i := len(ts) - 1; i >= 0 && encodedLength(ts) > opts.MaxLength; i-- {
+		if len(ts[i].String()) > largeMemberLength {
+			dropped = append(dropped, DroppedMember{Member: ts[i], Reason: DroppedSizeLimit})
+			ts = append(ts[:i:i], ts[i+1:]...)
+		}
+	}
+	for len(ts) > 0 && encodedLength(ts) > opts.MaxLength {
+		dropped = append(dropped, DroppedMember{Member: ts[len(ts)-1], Reason: DroppedSizeLimit})
+		ts = ts[:len(ts)-1]
+	}
+
+	return ts, dropped
+}
+
+func encodedLength(ts TraceState) int {
+	if len(ts) == 0 {
+		return 0
+	}
+	n := len(ts) - 1
+	for _, m := range ts {
+		n += len(m.String())
+	}
+	return n
+}
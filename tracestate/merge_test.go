@@ -0,0 +1,104 @@
+package tracestate_test
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/lightstep/tracecontext.go/tracestate"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe(".Merge", func() {
+	mustParse := func(s string) TraceState {
+		ts, err := ParseString(s)
+		Expect(err).NotTo(HaveOccurred())
+		return ts
+	}
+
+	It("orders members by first appearance and keeps the first by default", func() {
+		merged, dropped := Merge(MergeOptions{}, mustParse("a=1,b=1"), mustParse("c=2,a=2"), nil, mustParse("t@b=3,b=3"))
+		Expect(merged.String()).To(Equal("a=1,b=1,c=2,t@b=3"))
+		Expect(dropped).To(Equal([]DroppedMember{
+			{Member: member("a", "2"), Reason: DroppedConflict},
+			{Member: member("b", "3"), Reason: DroppedConflict},
+		}))
+		Expect(merged.Validate()).To(Succeed())
+	})
+
+	It("applies the conflict strategy configured for each key", func() {
+		concat := func(existing, incoming Member) Member {
+			existing.Value += "+" + incoming.Value
+			return existing
+		}
+		opts := MergeOptions{
+			Default:    MostRecentWins,
+			Strategies: map[string]ConflictStrategy{"b": FirstWins, "c": concat},
+		}
+
+		merged, dropped := Merge(opts, mustParse("a=1,b=1,c=1"), mustParse("a=2,b=2,c=2"))
+		Expect(merged.String()).To(Equal("a=2,b=1,c=1+2"))
+		Expect(dropped).To(Equal([]DroppedMember{
+			{Member: member("a", "1"), Reason: DroppedConflict},
+			{Member: member("b", "2"), Reason: DroppedConflict},
+			{Member: member("c", "1"), Reason: DroppedConflict},
+			{Member: member("c", "2"), Reason: DroppedConflict},
+		}))
+	})
+
+	It("drops invalid members and invalid resolutions", func() {
+		invalid := func(existing, incoming Member) Member {
+			existing.Value = "a,b"
+			return existing
+		}
+
+		merged, dropped := Merge(MergeOptions{Default: invalid}, TraceState{{SystemID: "A", Value: "1"}, member("b", "1")}, mustParse("b=2"))
+		Expect(merged.String()).To(Equal("b=1"))
+		Expect(dropped).To(Equal([]DroppedMember{
+			{Member: Member{SystemID: "A", Value: "1"}, Reason: DroppedInvalid},
+			{Member: Member{SystemID: "b", Value: "a,b"}, Reason: DroppedInvalid},
+			{Member: member("b", "2"), Reason: DroppedConflict},
+		}))
+	})
+
+	It("respects the member limit", func() {
+		var first, second []string
+		for i := 0; i < 20; i++ {
+			first = append(first, fmt.Sprintf("a%d=1", i))
+			second = append(second, fmt.Sprintf("b%d=2", i))
+		}
+
+		merged, dropped := Merge(MergeOptions{}, mustParse(strings.Join(first, ",")), mustParse(strings.Join(second, ",")))
+		Expect(merged).To(HaveLen(32))
+		Expect(merged[31].Key()).To(Equal("b11"))
+		Expect(dropped).To(HaveLen(8))
+		Expect(dropped[0]).To(Equal(DroppedMember{Member: member("b19", "2"), Reason: DroppedMemberLimit}))
+
+		merged, dropped = Merge(MergeOptions{MaxMembers: 2}, mustParse(strings.Join(first, ",")))
+		Expect(merged.String()).To(Equal("a0=1,a1=1"))
+		Expect(dropped).To(HaveLen(18))
+	})
+
+	It("respects the size limit, dropping large members first", func() {
+		large := "large=" + strings.Repeat("x", 130)
+		ts := mustParse("a=1," + large + ",b=2,c=3")
+
+		merged, dropped := Merge(MergeOptions{MaxLength: 11}, ts)
+		Expect(merged.String()).To(Equal("a=1,b=2,c=3"))
+		Expect(dropped).To(Equal([]DroppedMember{{Member: member("large", strings.Repeat("x", 130)), Reason: DroppedSizeLimit}}))
+
+		merged, dropped = Merge(MergeOptions{MaxLength: 8}, ts)
+		Expect(merged.String()).To(Equal("a=1,b=2"))
+		Expect(dropped).To(HaveLen(2))
+		Expect(dropped[1]).To(Equal(DroppedMember{Member: member("c", "3"), Reason: DroppedSizeLimit}))
+
+		merged, _ = Merge(MergeOptions{MaxLength: 1}, ts)
+		Expect(merged).To(BeEmpty())
+		Expect(ts.String()).To(Equal("a=1," + large + ",b=2,c=3"))
+	})
+
+	It("describes why members were dropped", func() {
+		Expect(DroppedConflict.String()).To(Equal("conflict"))
+		Expect(DroppedSizeLimit.String()).To(Equal("size limit"))
+	})
+})
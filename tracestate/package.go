@@ -10,42 +10,174 @@ import (
 var (
 	// ErrInvalidListMember occurs if at least one list member is invalid, e.g., contains an unexpected character.
 	ErrInvalidListMember = errors.New("tracecontext: Invalid tracestate list member")
-	// ErrDuplicateListMemberKey occurs if at least two list members contain the same vendor-tenant pair.
+	// ErrDuplicateListMemberKey occurs if at least two list members contain the same key.
 	ErrDuplicateListMemberKey = errors.New("tracecontext: Duplicate list member key in tracestate")
 	// ErrTooManyListMembers occurs if the list contains more than the maximum number of members per the spec, i.e., 32.
 	ErrTooManyListMembers = errors.New("tracecontext: Too many list members in tracestate")
+	// ErrInvalidKey occurs if a list member's key, tenant ID or system ID contains an unexpected character, starts with a disallowed character or is too long.
+	ErrInvalidKey = errors.New("tracecontext: Invalid tracestate list member key")
+	// ErrInvalidValue occurs if a list member's value is empty, contains an unexpected character, ends with a space or is longer than 256 characters.
+	ErrInvalidValue = errors.New("tracecontext: Invalid tracestate list member value")
 )
 
 const (
 	maxMembers = 32
 
-	delimiter = ","
+	delimiter       = ","
+	tenantDelimiter = "@"
 )
 
+// The key and value grammar of the W3C Recommendation:
+//
+//	simple-key       = lcalpha 0*255( lcalpha / DIGIT / "_" / "-"/ "*" / "/" )
+//	multi-tenant-key = tenant-id "@" system-id
+//	tenant-id        = ( lcalpha / DIGIT ) 0*240( lcalpha / DIGIT / "_" / "-"/ "*" / "/" )
+//	system-id        = lcalpha 0*13( lcalpha / DIGIT / "_" / "-"/ "*" / "/" )
+//	value            = 0*255(chr) nblk-chr
 var (
-	re = regexp.MustCompile(`^\s*(?:([a-z0-9_\-*/]{1,241})@([a-z0-9_\-*/]{1,14})|([a-z0-9_\-*/]{1,256}))=([\x20-\x2b\x2d-\x3c\x3e-\x7e]*[\x21-\x2b\x2d-\x3c\x3e-\x7e])\s*$`)
+	re = regexp.MustCompile(`^\s*(?:([a-z0-9][a-z0-9_\-*/]{0,240})@([a-z][a-z0-9_\-*/]{0,13})|([a-z][a-z0-9_\-*/]{0,255}))=([\x20-\x2b\x2d-\x3c\x3e-\x7e]{0,255}[\x21-\x2b\x2d-\x3c\x3e-\x7e])\s*$`)
+
+	simpleKeyRe = regexp.MustCompile(`^[a-z][a-z0-9_\-*/]{0,255}$`)
+	tenantIDRe  = regexp.MustCompile(`^[a-z0-9][a-z0-9_\-*/]{0,240}$`)
+	systemIDRe  = regexp.MustCompile(`^[a-z][a-z0-9_\-*/]{0,13}$`)
+	valueRe     = regexp.MustCompile(`^[\x20-\x2b\x2d-\x3c\x3e-\x7e]{0,255}[\x21-\x2b\x2d-\x3c\x3e-\x7e]$`)
 )
 
 // Member contains vendor-specific data that should be propagated across all new spans started within a given trace.
+//
+// Its key is either a simple key, e.g., `congo`, or a multi-tenant key, e.g., `t61@congo`,
+// formed from the ID of a tenant of a tracing system and the ID of the system itself.
+//
+// Earlier versions stored the key in `Vendor` and `Tenant`, where `Vendor` held the simple key or the part of a multi-tenant key
+// before the `@`, and `Tenant` the part after it. Those fields are deprecated: they are only read if `TenantID` and `SystemID` are both empty,
+// and are never set by this package. To migrate, replace `Member{Vendor: k}` with `Member{SystemID: k}`
+// and `Member{Vendor: t, Tenant: s}` with `Member{TenantID: t, SystemID: s}`, or use `NewSimpleMember` and `NewMultiTenantMember`.
+// A `Member` that sets both the deprecated and the current key fields is invalid.
 type Member struct {
-	// Vendor is a key representing a particular trace vendor.
+	// TenantID is the part of a multi-tenant key before the `@`. It is empty for a simple key.
+	TenantID string
+	// SystemID is the simple key, or the part of a multi-tenant key after the `@`.
+	SystemID string
+	// Value is the particular data that the vendor intends to pass to child spans.
+	Value string
+
+	// Vendor is the simple key, or the part of a multi-tenant key before the `@`.
+	//
+	// Deprecated: Use `SystemID` for a simple key, or `TenantID` for a multi-tenant key.
 	Vendor string
-	// Tenant is a key used to distinguish between tenants of a multi-tenant trace vendor.
+	// Tenant is the part of a multi-tenant key after the `@`.
+	//
+	// Deprecated: Use `SystemID`.
 	Tenant string
-	// Value is the particular data that the vendor intents to pass to child spans.
-	Value string
+}
+
+// NewSimpleMember returns a `Member` with a simple key. It returns an error if the key or value is invalid.
+func NewSimpleMember(key, value string) (Member, error) {
+	m := Member{
+		SystemID: key,
+		Value:    value,
+	}
+	return m, m.Validate()
+}
+
+// NewMultiTenantMember returns a `Member` with the multi-tenant key `tenantID@systemID`.
+// It returns an error if the tenant ID, system ID or value is invalid.
+func NewMultiTenantMember(tenantID, systemID, value string) (Member, error) {
+	m := Member{
+		TenantID: tenantID,
+		SystemID: systemID,
+		Value:    value,
+	}
+	if err := validateKey(tenantID, systemID, true); err != nil {
+		return m, err
+	}
+	return m, ValidateValue(value)
+}
+
+// keyParts returns the parts of the `Member`'s key, from the deprecated fields if the current ones are empty.
+func (m Member) keyParts() (tenantID, systemID string, multiTenant bool) {
+	if m.TenantID == "" && m.SystemID == "" {
+		if m.Tenant == "" {
+			return "", m.Vendor, false
+		}
+		return m.Vendor, m.Tenant, true
+	}
+	return m.TenantID, m.SystemID, m.TenantID != ""
+}
+
+// ambiguousKey returns whether both the deprecated and the current key fields are set, so that the key is ambiguous.
+func (m Member) ambiguousKey() bool {
+	return (m.TenantID != "" || m.SystemID != "") && (m.Vendor != "" || m.Tenant != "")
+}
+
+// Key returns the `Member`'s key: the system ID for a simple key, or `tenantID@systemID` for a multi-tenant key.
+func (m Member) Key() string {
+	tenantID, systemID, multiTenant := m.keyParts()
+	if !multiTenant {
+		return systemID
+	}
+	return tenantID + tenantDelimiter + systemID
+}
+
+// Validate checks that the `Member`'s key and value are valid according to the W3C spec.
+// It returns `ErrInvalidKey` if the key is invalid or sets both the deprecated and the current key fields, or `ErrInvalidValue` if the value is invalid.
+func (m Member) Validate() error {
+	if m.ambiguousKey() {
+		return ErrInvalidKey
+	}
+	if err := validateKey(m.keyParts()); err != nil {
+		return err
+	}
+	return ValidateValue(m.Value)
+}
+
+func validateKey(tenantID, systemID string, multiTenant bool) error {
+	if !multiTenant {
+		if !simpleKeyRe.MatchString(systemID) {
+			return ErrInvalidKey
+		}
+		return nil
+	}
+	if !tenantIDRe.MatchString(tenantID) || !systemIDRe.MatchString(systemID) {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+// ParseKey splits a list member key into its tenant ID, which is empty for a simple key, and its system ID.
+// It returns `ErrInvalidKey` if the key is invalid.
+func ParseKey(key string) (tenantID, systemID string, err error) {
+	i := strings.Index(key, tenantDelimiter)
+	if i < 0 {
+		tenantID, systemID = "", key
+	} else {
+		tenantID, systemID = key[:i], key[i+1:]
+	}
+	if err := validateKey(tenantID, systemID, i >= 0); err != nil {
+		return "", "", err
+	}
+	return tenantID, systemID, nil
+}
+
+// ValidateValue checks that a list member value is valid, i.e., 1 to 256 printable ASCII characters,
+// excluding `,` and `=`, that do not end with a space.
+// It returns `ErrInvalidValue` if the value is invalid.
+func ValidateValue(value string) error {
+	if !valueRe.MatchString(value) {
+		return ErrInvalidValue
+	}
+	return nil
 }
 
 // String encodes a `Member` into a string formatted according to the W3C spec.
-// The string may be invalid if any fields are invalid, e.g, the vendor contains a non-compliant character.
+// The string may be invalid if any fields are invalid, e.g, the key contains a non-compliant character.
 func (m Member) String() string {
-	if m.Tenant == "" {
-		return fmt.Sprintf("%s=%s", m.Vendor, m.Value)
-	}
-	return fmt.Sprintf("%s@%s=%s", m.Vendor, m.Tenant, m.Value)
+	return fmt.Sprintf("%s=%s", m.Key(), m.Value)
 }
 
 // TraceState represents a list of `Member`s that should be propagated to new spans started in a trace.
+// As a slice, a copied `TraceState` shares its backing array with the original, so appending to or modifying either may affect the other.
+// Use `Immutable` to share a `TraceState` between goroutines, e.g., when deriving several child contexts from the same parent.
 type TraceState []Member
 
 // String encodes all `Member`s of the `TraceState` into a single string, formatted according to the W3C spec.
@@ -58,6 +190,77 @@ func (ts TraceState) String() string {
 	return strings.Join(members, ",")
 }
 
+// Validate checks that every `Member` of the `TraceState` is valid, that no two `Member`s share a key,
+// and that the list does not exceed the maximum number of members.
+// It returns `ErrTooManyListMembers` if the list is too long, `ErrInvalidKey` or `ErrInvalidValue` for an invalid `Member`,
+// unlike `Parse`, which returns `ErrInvalidListMember`, or `ErrDuplicateListMemberKey` if two `Member`s share a key.
+func (ts TraceState) Validate() error {
+	if len(ts) > maxMembers {
+		return ErrTooManyListMembers
+	}
+
+	found := make(map[string]interface{})
+	for _, member := range ts {
+		if err := member.Validate(); err != nil {
+			return err
+		}
+
+		key := member.Key()
+		if _, ok := found[key]; ok {
+			return ErrDuplicateListMemberKey
+		}
+		found[key] = nil
+	}
+
+	return nil
+}
+
+// GetKey returns the `Member` with the given key, and whether such a `Member` exists.
+func (ts TraceState) GetKey(key string) (Member, bool) {
+	for _, member := range ts {
+		if member.Key() == key {
+			return member, true
+		}
+	}
+	return Member{}, false
+}
+
+// Set returns a copy of the `TraceState` with the given `Member` at the front of the list,
+// replacing any existing `Member` with the same key, as the spec requires for updated list members.
+// It returns an error if the `Member` is invalid or the list would contain too many members.
+func (ts TraceState) Set(m Member) (TraceState, error) {
+	if err := m.Validate(); err != nil {
+		return ts, err
+	}
+
+	key := m.Key()
+
+	updated := make(TraceState, 0, len(ts)+1)
+	updated = append(updated, m)
+	for _, member := range ts {
+		if member.Key() != key {
+			updated = append(updated, member)
+		}
+	}
+
+	if len(updated) > maxMembers {
+		return ts, ErrTooManyListMembers
+	}
+
+	return updated, nil
+}
+
+// DeleteKey returns a copy of the `TraceState` without the `Member` with the given key.
+func (ts TraceState) DeleteKey(key string) TraceState {
+	var updated TraceState
+	for _, member := range ts {
+		if member.Key() != key {
+			updated = append(updated, member)
+		}
+	}
+	return updated
+}
+
 // Parse attempts to decode a `TraceState` from a byte array.
 // It returns an error if the byte array is invalid, e.g., it contains an incorrectly formatted list member.
 func Parse(traceState []byte) (TraceState, error) {
@@ -86,7 +289,7 @@ func parse(traceState string) (ts TraceState, err error) {
 			return
 		}
 
-		key := fmt.Sprintf("%s%s", m.Vendor, m.Tenant)
+		key := m.Key()
 		if _, ok := found[key]; ok {
 			err = ErrDuplicateListMemberKey
 			return
@@ -110,14 +313,8 @@ func parseMember(s string) (Member, error) {
 		return Member{}, ErrInvalidListMember
 	}
 
-	vendor := matches[1]
-	if vendor == "" {
-		vendor = matches[3]
+	if matches[3] != "" {
+		return Member{SystemID: matches[3], Value: matches[4]}, nil
 	}
-
-	return Member{
-		Vendor: vendor,
-		Tenant: matches[2],
-		Value:  matches[4],
-	}, nil
+	return Member{TenantID: matches[1], SystemID: matches[2], Value: matches[4]}, nil
 }
@@ -0,0 +1,30 @@
+package tracestate
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Value implements `driver.Valuer`, storing the `TraceState` as text, or as NULL if it is empty.
+func (ts TraceState) Value() (driver.Value, error) {
+	if len(ts) == 0 {
+		return nil, nil
+	}
+	return ts.String(), nil
+}
+
+// Scan implements `sql.Scanner`, accepting the text form as a string or byte slice.
+// As with `Parse`, NULL and the empty string result in an empty `TraceState`.
+func (ts *TraceState) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*ts = nil
+		return nil
+	case string:
+		return ts.UnmarshalText([]byte(src))
+	case []byte:
+		return ts.UnmarshalText(src)
+	default:
+		return fmt.Errorf("tracecontext: Cannot scan %T into tracestate", src)
+	}
+}
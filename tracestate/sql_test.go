@@ -0,0 +1,39 @@
+package tracestate_test
+
+import (
+	. "github.com/lightstep/tracecontext.go/tracestate"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SQL", func() {
+	It("stores the tracestate as text, or NULL if empty", func() {
+		v, err := TraceState{{SystemID: "a", Value: "1"}}.Value()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal("a=1"))
+
+		v, err = TraceState(nil).Value()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(BeNil())
+	})
+
+	It("scans text, treating NULL as empty", func() {
+		ts := TraceState{member("a", "1")}
+
+		for _, src := range []interface{}{"a=1", []byte("a=1")} {
+			var scanned TraceState
+			Expect(scanned.Scan(src)).To(Succeed())
+			Expect(scanned).To(Equal(ts))
+		}
+
+		scanned := ts
+		Expect(scanned.Scan(nil)).To(Succeed())
+		Expect(scanned).To(BeEmpty())
+	})
+
+	It("applies the same validation as parsing", func() {
+		var scanned TraceState
+		Expect(scanned.Scan("a=1,a=2")).To(MatchError("tracecontext: Duplicate list member key in tracestate"))
+		Expect(scanned.Scan(1.5)).To(MatchError("tracecontext: Cannot scan float64 into tracestate"))
+	})
+})
@@ -14,8 +14,10 @@ import (
 )
 
 const (
-	allowedVendorChars        = "abcdefghijklmnopqrstuvwxyz0123456789_-*/"
-	allowedTenantChars        = allowedVendorChars
+	allowedKeyFirstChars      = "abcdefghijklmnopqrstuvwxyz"
+	allowedTenantIDFirstChars = allowedKeyFirstChars + "0123456789"
+	allowedSystemIDFirstChars = allowedKeyFirstChars
+	allowedKeyChars           = "abcdefghijklmnopqrstuvwxyz0123456789_-*/"
 	allowedValueNonBlankChars = "!\"#$%&'()*+-./0123456789:;<>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
 	allowedValueChars         = allowedValueNonBlankChars + " "
 )
@@ -31,11 +33,265 @@ var _ = Describe(".Parse", func() {
 	})
 })
 
+// member returns a valid `Member` with the given key and value, as `Parse` would decode it.
+func member(key, value string) Member {
+	m, err := ParseString(key + "=" + value)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(m).To(HaveLen(1))
+	return m[0]
+}
+
+var _ = Describe(".NewSimpleMember and .NewMultiTenantMember", func() {
+	It("return a valid member", func() {
+		quick.Check(func(tm TestMember) bool {
+			tm.Clean()
+
+			var m Member
+			var err error
+			if tm.TenantID == "" {
+				m, err = NewSimpleMember(tm.SystemID, tm.Value)
+			} else {
+				m, err = NewMultiTenantMember(tm.TenantID, tm.SystemID, tm.Value)
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.TenantID).To(Equal(tm.TenantID))
+			Expect(m.SystemID).To(Equal(tm.SystemID))
+			Expect(m.Value).To(Equal(tm.Value))
+			Expect(m.Key()).To(Equal(tm.Key()))
+			Expect(m.String()).To(Equal(tm.String()))
+			Expect(m).To(Equal(member(tm.Key(), tm.Value)))
+
+			return true
+		}, nil)
+	})
+
+	It("error if the key is invalid", func() {
+		for _, key := range []string{"", "Key", "1key", "*key", "_key", "k ey", "a@b", strings.Repeat("a", 257)} {
+			_, err := NewSimpleMember(key, "value")
+			Expect(err).To(MatchError("tracecontext: Invalid tracestate list member key"), key)
+		}
+		for _, key := range [][2]string{
+			{"", "system"},
+			{"tenant", ""},
+			{"Tenant", "system"},
+			{"_tenant", "system"},
+			{"tenant", "System"},
+			{"tenant", "1system"},
+			{strings.Repeat("a", 242), "system"},
+			{"tenant", strings.Repeat("a", 15)},
+		} {
+			_, err := NewMultiTenantMember(key[0], key[1], "value")
+			Expect(err).To(MatchError("tracecontext: Invalid tracestate list member key"), key[0]+"@"+key[1])
+		}
+	})
+
+	It("error if the value is invalid", func() {
+		for _, value := range []string{"", " ", "value ", "val,ue", "val=ue", "valu\x00e", strings.Repeat("a", 257)} {
+			_, err := NewSimpleMember("key", value)
+			Expect(err).To(MatchError("tracecontext: Invalid tracestate list member value"), value)
+			_, err = NewMultiTenantMember("tenant", "system", value)
+			Expect(err).To(MatchError("tracecontext: Invalid tracestate list member value"), value)
+		}
+	})
+})
+
+var _ = Describe(".ParseKey", func() {
+	It("splits keys into their tenant and system IDs", func() {
+		quick.Check(func(tm TestMember) bool {
+			tm.Clean()
+
+			tenantID, systemID, err := ParseKey(tm.Key())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tenantID).To(Equal(tm.TenantID))
+			Expect(systemID).To(Equal(tm.SystemID))
+
+			return true
+		}, nil)
+	})
+
+	It("accepts keys at each boundary of the grammar", func() {
+		for _, key := range []string{
+			"a",
+			"a" + strings.Repeat("z", 255),
+			"a0_-*/",
+			"0@a",
+			"a@a",
+			"0" + strings.Repeat("/", 240) + "@a" + strings.Repeat("*", 13),
+		} {
+			_, _, err := ParseKey(key)
+			Expect(err).NotTo(HaveOccurred(), key)
+		}
+	})
+
+	It("rejects keys beyond each boundary of the grammar", func() {
+		for _, key := range []string{
+			"",
+			"a" + strings.Repeat("z", 256),
+			"0",
+			"_",
+			"-a",
+			"*a",
+			"/a",
+			"A",
+			"@a",
+			"a@",
+			"_@a",
+			"a@0",
+			"a@_",
+			"a@b@c",
+			"0" + strings.Repeat("a", 241) + "@a",
+			"a@a" + strings.Repeat("a", 14),
+		} {
+			_, _, err := ParseKey(key)
+			Expect(err).To(MatchError("tracecontext: Invalid tracestate list member key"), key)
+		}
+	})
+
+	It("rejects keys that start with a disallowed character", func() {
+		quick.Check(func(tm TestMember, c byte) bool {
+			tm.Clean()
+			if strings.IndexByte(allowedKeyFirstChars, c) >= 0 {
+				return true
+			}
+
+			_, _, err := ParseKey(string(c) + tm.SystemID[1:])
+			Expect(err).To(HaveOccurred())
+			if strings.IndexByte(allowedTenantIDFirstChars, c) < 0 && tm.TenantID != "" {
+				_, _, err = ParseKey(string(c) + tm.TenantID[1:] + "@" + tm.SystemID)
+				Expect(err).To(HaveOccurred())
+			}
+			return true
+		}, nil)
+	})
+})
+
+var _ = Describe(".ValidateValue", func() {
+	It("accepts values at each boundary of the grammar", func() {
+		for _, value := range []string{"!", "~", " a", "a b", strings.Repeat(" ", 255) + "a", strings.Repeat("a", 256)} {
+			Expect(ValidateValue(value)).To(Succeed(), value)
+		}
+	})
+
+	It("rejects values beyond each boundary of the grammar", func() {
+		for _, value := range []string{"", " ", "a ", "a,", "a=", "\x7f", "\t", strings.Repeat("a", 257), strings.Repeat(" ", 256) + "a"} {
+			Expect(ValidateValue(value)).To(MatchError("tracecontext: Invalid tracestate list member value"), value)
+		}
+	})
+})
+
+var _ = Describe("TraceState#Validate", func() {
+	It("allows valid trace states", func() {
+		ts := TraceState{
+			{SystemID: "vendor", Value: "value"},
+			{TenantID: "vendor", SystemID: "tenant", Value: "value"},
+		}
+		Expect(ts.Validate()).To(Succeed())
+		Expect(TraceState(nil).Validate()).To(Succeed())
+	})
+
+	It("errors if a member is invalid", func() {
+		ts := TraceState{{SystemID: "vendor", Value: "value"}, {SystemID: "Vendor", Value: "value"}}
+		Expect(ts.Validate()).To(MatchError("tracecontext: Invalid tracestate list member key"))
+	})
+
+	It("errors if there are duplicate keys", func() {
+		ts := TraceState{{SystemID: "vendor", Value: "a"}, {SystemID: "vendor", Value: "b"}}
+		Expect(ts.Validate()).To(MatchError("tracecontext: Duplicate list member key in tracestate"))
+	})
+
+	It("distinguishes simple keys from multi-tenant keys with the same characters", func() {
+		ts := TraceState{{SystemID: "ab", Value: "1"}, {TenantID: "a", SystemID: "b", Value: "2"}}
+		Expect(ts.Validate()).To(Succeed())
+
+		parsed, err := ParseString(ts.String())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(HaveLen(2))
+	})
+
+	It("errors if there are more than 32 members", func() {
+		var ts TraceState
+		for i := 0; i < 33; i++ {
+			ts = append(ts, Member{SystemID: fmt.Sprintf("vendor%d", i), Value: "value"})
+		}
+		Expect(ts[:32].Validate()).To(Succeed())
+		Expect(ts.Validate()).To(MatchError("tracecontext: Too many list members in tracestate"))
+	})
+})
+
+var _ = Describe("TraceState#Set", func() {
+	It("moves the updated member to the front without modifying the original", func() {
+		ts := TraceState{{SystemID: "a", Value: "1"}, {SystemID: "b", Value: "2"}, {TenantID: "b", SystemID: "t", Value: "3"}}
+
+		updated, err := ts.Set(Member{SystemID: "b", Value: "4"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.String()).To(Equal("b=4,a=1,b@t=3"))
+		Expect(ts.String()).To(Equal("a=1,b=2,b@t=3"))
+
+		m, ok := updated.GetKey("b@t")
+		Expect(ok).To(BeTrue())
+		Expect(m.Value).To(Equal("3"))
+
+		Expect(updated.DeleteKey("a").String()).To(Equal("b=4,b@t=3"))
+	})
+
+	It("decodes the tenant and system IDs of parsed members", func() {
+		ts, err := ParseString("foo=1,t@b=2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ts).To(Equal(TraceState{{SystemID: "foo", Value: "1"}, {TenantID: "t", SystemID: "b", Value: "2"}}))
+
+		ts[0].SystemID = "bar"
+		Expect(ts.String()).To(Equal("bar=1,t@b=2"))
+
+		updated, err := ts.Set(member("b", "3"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.String()).To(Equal("b=3,bar=1,t@b=2"))
+
+		_, ok := updated.GetKey("foo")
+		Expect(ok).To(BeFalse())
+		Expect(updated.DeleteKey("t@b").String()).To(Equal("b=3,bar=1"))
+	})
+
+	It("uses the deprecated key fields only if the current ones are empty", func() {
+		ts := TraceState{{Vendor: "a", Value: "1"}, {Vendor: "t", Tenant: "b", Value: "2"}}
+		Expect(ts.Validate()).To(Succeed())
+		Expect(ts.String()).To(Equal("a=1,t@b=2"))
+
+		m, ok := ts.GetKey("t@b")
+		Expect(ok).To(BeTrue())
+		Expect(m.Value).To(Equal("2"))
+
+		updated, err := ts.Set(member("a", "3"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.String()).To(Equal("a=3,t@b=2"))
+
+		parsed, err := ParseString("foo=1")
+		Expect(err).NotTo(HaveOccurred())
+		parsed[0].Vendor = "bar"
+		Expect(parsed.Validate()).To(MatchError("tracecontext: Invalid tracestate list member key"))
+		_, err = TraceState{}.Set(parsed[0])
+		Expect(err).To(MatchError("tracecontext: Invalid tracestate list member key"))
+	})
+
+	It("errors if the member is invalid or the list would be too long", func() {
+		_, err := TraceState{}.Set(Member{SystemID: "a", Value: "1 "})
+		Expect(err).To(MatchError("tracecontext: Invalid tracestate list member value"))
+
+		var ts TraceState
+		for i := 0; i < 32; i++ {
+			ts = append(ts, Member{SystemID: fmt.Sprintf("vendor%d", i), Value: "value"})
+		}
+		_, err = ts.Set(Member{SystemID: "vendor0", Value: "updated"})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ts.Set(Member{SystemID: "vendor", Value: "value"})
+		Expect(err).To(MatchError("tracecontext: Too many list members in tracestate"))
+	})
+})
+
 func testParsing(parse func(string) (TraceState, error)) {
 	It("allows up to 32 valid, non-empty list members", func() {
 		quick.Check(func(testMembers []TestMember) bool {
 			var expectedMembers []Member
-			vendors := make(map[string]interface{})
+			keys := make(map[string]interface{})
 
 			var memberStrings []string
 
@@ -45,21 +301,13 @@ func testParsing(parse func(string) (TraceState, error)) {
 				}
 
 				tm.Clean()
-				if tm.Vendor == "" || tm.Value == "" {
-					continue
-				}
 
-				vendorKey := fmt.Sprintf("%s@%s", tm.Vendor, tm.Tenant)
-				if _, ok := vendors[vendorKey]; ok {
+				if _, ok := keys[tm.Key()]; ok {
 					continue
 				}
-				vendors[vendorKey] = nil
+				keys[tm.Key()] = nil
 
-				expectedMembers = append(expectedMembers, Member{
-					Vendor: tm.Vendor,
-					Tenant: tm.Tenant,
-					Value:  tm.Value,
-				})
+				expectedMembers = append(expectedMembers, tm.Member())
 				memberStrings = append(memberStrings, tm.String())
 
 				if len(expectedMembers) == 32 {
@@ -77,52 +325,75 @@ func testParsing(parse func(string) (TraceState, error)) {
 }
 
 type TestMember struct {
-	Vendor string
-	Tenant string
-	Value  string
+	TenantID string
+	SystemID string
+	Value    string
 }
 
-func (m TestMember) String() string {
-	if m.Tenant == "" {
-		return fmt.Sprintf("%s=%s", m.Vendor, m.Value)
+func (m TestMember) Key() string {
+	if m.TenantID == "" {
+		return m.SystemID
 	}
-	return fmt.Sprintf("%s@%s=%s", m.Vendor, m.Tenant, m.Value)
+	return fmt.Sprintf("%s@%s", m.TenantID, m.SystemID)
 }
 
+func (m TestMember) String() string {
+	return fmt.Sprintf("%s=%s", m.Key(), m.Value)
+}
+
+// Member returns the `Member` that this package should decode.
+func (m TestMember) Member() Member {
+	return Member{TenantID: m.TenantID, SystemID: m.SystemID, Value: m.Value}
+}
+
+// Clean truncates each field to its maximum length, keeping the last character of the value so that it remains non-blank.
 func (m *TestMember) Clean() {
-	if m.Tenant == "" {
-		if len(m.Vendor) > 256 {
-			m.Vendor = m.Vendor[:256]
+	if m.TenantID == "" {
+		if len(m.SystemID) > 256 {
+			m.SystemID = m.SystemID[:256]
 		}
 	} else {
-		if len(m.Vendor) > 241 {
-			m.Vendor = m.Vendor[:241]
+		if len(m.TenantID) > 241 {
+			m.TenantID = m.TenantID[:241]
 		}
-		if len(m.Tenant) > 14 {
-			m.Tenant = m.Tenant[:14]
+		if len(m.SystemID) > 14 {
+			m.SystemID = m.SystemID[:14]
 		}
 	}
 
 	if len(m.Value) > 256 {
-		m.Value = m.Value[:256]
+		m.Value = m.Value[:255] + m.Value[len(m.Value)-1:]
 	}
 }
 
 func (m TestMember) Generate(rg *rand.Rand, size int) reflect.Value {
-	vendor := make([]byte, size)
-	var tenant []byte
-	hasTenant := rg.Int()%1 == 0
-	if hasTenant {
-		tenant = make([]byte, size)
+	// Each field must have at least one character, and simple keys and values may be longer than `size` allows.
+	size++
+	hasTenantID := rg.Intn(2) == 0
+
+	var tenantID []byte
+	systemID := make([]byte, size+rg.Intn(2)*rg.Intn(300))
+	value := make([]byte, size+rg.Intn(2)*rg.Intn(300))
+	if hasTenantID {
+		tenantID = make([]byte, size+rg.Intn(2)*rg.Intn(300))
 	}
-	value := make([]byte, size)
 
-	for i := 0; i < size; i++ {
-		vendor[i] = randChar(rg, allowedVendorChars)
-		if hasTenant {
-			tenant[i] = randChar(rg, allowedTenantChars)
+	for i := range tenantID {
+		if i == 0 {
+			tenantID[i] = randChar(rg, allowedTenantIDFirstChars)
+		} else {
+			tenantID[i] = randChar(rg, allowedKeyChars)
 		}
-		if i == size-1 {
+	}
+	for i := range systemID {
+		if i == 0 {
+			systemID[i] = randChar(rg, allowedSystemIDFirstChars)
+		} else {
+			systemID[i] = randChar(rg, allowedKeyChars)
+		}
+	}
+	for i := range value {
+		if i == len(value)-1 {
 			value[i] = randChar(rg, allowedValueNonBlankChars)
 		} else {
 			value[i] = randChar(rg, allowedValueChars)
@@ -130,8 +401,8 @@ func (m TestMember) Generate(rg *rand.Rand, size int) reflect.Value {
 	}
 
 	v := reflect.New(reflect.TypeOf(m)).Elem()
-	v.Field(0).SetString(string(vendor))
-	v.Field(1).SetString(string(tenant))
+	v.Field(0).SetString(string(tenantID))
+	v.Field(1).SetString(string(systemID))
 	v.Field(2).SetString(string(value))
 
 	return v